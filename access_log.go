@@ -0,0 +1,201 @@
+package gohera
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// accessLogConfig [log.access] 配置
+type accessLogConfig struct {
+	Enable        bool          `mapstructure:"enable"`
+	File          string        `mapstructure:"file"`                           // 请求日志文件名
+	SkipPaths     []string      `mapstructure:"skip_paths"`                     // 不记录的路径，以 * 结尾时按前缀匹配
+	RequestBody   bool          `mapstructure:"request_body"`                   // 是否记录请求内容
+	ResponseBody  bool          `mapstructure:"response_body"`                  // 是否记录响应内容
+	MaxBodySize   int           `mapstructure:"max_body_size" binding:"gte=0"`  // 记录的请求及响应内容的最大字节数，0 表示不限制
+	ContentTypes  []string      `mapstructure:"content_types"`                  // 记录内容的 Content-Type，按包含匹配
+	SlowThreshold time.Duration `mapstructure:"slow_threshold" binding:"gte=0"` // 慢请求阈值，超过时以 Warn 级别记录，0 表示不开启
+}
+
+// defaultAccessLogConfig 默认请求日志配置
+func defaultAccessLogConfig() accessLogConfig {
+	return accessLogConfig{
+		Enable:       true,
+		File:         "access.log",
+		SkipPaths:    []string{"/healthz", "/healthz/*"},
+		MaxBodySize:  4096,
+		ContentTypes: []string{"application/json", "application/x-www-form-urlencoded", "application/xml", "text/"},
+	}
+}
+
+var (
+	// accessLogger 请求日志的输出，未初始化前丢弃所有日志
+	accessLogger  = zap.NewNop()
+	accessLogConf atomic.Pointer[accessLogConfig]
+)
+
+func init() {
+	conf := defaultAccessLogConfig()
+	accessLogConf.Store(&conf)
+}
+
+// watchAccessLogConfig 配置热更新时重新应用请求日志配置，日志文件名修改后需要重启生效
+func watchAccessLogConfig() {
+	OnChange("log.access", func(_, _ any) {
+		cfg, err := loadLoggerConfig()
+		if err != nil {
+			Errortf(context.Background(), "log access config fail: %v", err)
+			return
+		}
+		accessLogConf.Store(&cfg.Access)
+	})
+}
+
+// skip 请求路径是否不需要记录
+func (c *accessLogConfig) skip(path string) bool {
+	return matchPath(c.SkipPaths, path)
+}
+
+// matchPath 请求路径是否匹配其中之一，以 * 结尾时按前缀匹配
+func matchPath(patterns []string, path string) bool {
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == p {
+			return true
+		}
+	}
+	return false
+}
+
+// captureContentType 是否记录该类型的请求或响应内容
+func (c *accessLogConfig) captureContentType(contentType string) bool {
+	for _, t := range c.ContentTypes {
+		if strings.Contains(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessLogCtx 请求日志中间件已执行的标记
+const accessLogCtx = "access-log-ctx"
+
+// HandleAppAccessLog 记录请求日志的中间件，需要在 TraceContext 之后使用，New 创建的默认引擎已注册
+// 日志写入独立的 access.log，包含 Trace 信息、请求方法、状态码、耗时、客户端 IP、请求及响应大小，
+// 按 [log.access] 配置记录脱敏后的请求及响应内容，超过慢请求阈值时以 Warn 级别记录；
+// 同一请求中重复注册时只记录一次
+func HandleAppAccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(accessLogCtx); ok {
+			c.Next()
+			return
+		}
+		c.Set(accessLogCtx, true)
+		conf := accessLogConf.Load()
+		if !conf.Enable || conf.skip(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		// 统计实际读取的请求内容大小，分块传输的请求没有 Content-Length
+		var reqCounter *countingReader
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			reqCounter = &countingReader{Reader: c.Request.Body}
+			c.Request.Body = readCloser{Reader: reqCounter, Closer: c.Request.Body}
+		}
+		var reqBody []byte
+		reqType := c.ContentType()
+		if conf.RequestBody && reqCounter != nil && conf.captureContentType(reqType) {
+			// 只读取需要记录的部分，剩余内容原样交给后续处理
+			var body io.Reader = c.Request.Body
+			if conf.MaxBodySize > 0 {
+				body = io.LimitReader(body, int64(conf.MaxBodySize))
+			}
+			reqBody, _ = io.ReadAll(body)
+			c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(reqBody), c.Request.Body), Closer: c.Request.Body}
+		}
+		var rspWriter *responseBodyWriter
+		if conf.ResponseBody {
+			rspWriter = &responseBodyWriter{ResponseWriter: c.Writer, bodyBuf: new(bytes.Buffer), limit: conf.MaxBodySize}
+			c.Writer = rspWriter
+		}
+
+		c.Next()
+
+		latency := time.Since(start)
+		// 状态码只记录在请求日志中，不修改其他协程可能正在读取的 Trace
+		fields := getContextFields(c)
+		for i := range fields {
+			if fields[i].Key == "x_status" {
+				fields[i] = zap.Int("x_status", c.Writer.Status())
+			}
+		}
+		fields = append(fields,
+			zap.String("x_method", c.Request.Method),
+			zap.String("x_query", maskQuery(c.Request.URL.RawQuery)),
+			zap.Float64("x_latency", float64(latency.Microseconds())/1000),
+			zap.String("x_client_ip", c.ClientIP()),
+			zap.String("x_user_agent", c.Request.UserAgent()),
+			zap.Int64("x_request_size", reqCounter.size(c.Request.ContentLength)),
+			zap.Int("x_response_size", c.Writer.Size()),
+		)
+		if reqBody != nil {
+			fields = append(fields, zap.String("x_request_body", maskBody(reqType, reqBody)))
+		}
+		if rspWriter != nil {
+			rspType := c.Writer.Header().Get("Content-Type")
+			if conf.captureContentType(rspType) {
+				fields = append(fields, zap.String("x_response_body", maskBody(rspType, rspWriter.bodyBuf.Bytes())))
+			}
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("x_error", c.Errors.String()))
+		}
+
+		msg := c.Request.Method + " " + c.Request.URL.Path
+		if conf.SlowThreshold > 0 && latency >= conf.SlowThreshold {
+			accessLogger.Warn("slow request "+msg, fields...)
+			return
+		}
+		accessLogger.Info(msg, fields...)
+	}
+}
+
+// countingReader 统计读取的字节数
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+// Read 实现 io.Reader 接口
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// size 请求内容的大小，未读取完时使用 Content-Length，没有请求内容时为 0
+func (r *countingReader) size(contentLength int64) int64 {
+	if r == nil {
+		return 0
+	}
+	return max(r.n, contentLength)
+}
+
+// readCloser 组合读取及关闭，用于替换已部分读取的请求内容
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,177 @@
+package gohera
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observeAccessLogs 将请求日志输出替换为内存中的 observer 并使用 cfg，测试结束后恢复
+func observeAccessLogs(t *testing.T, cfg accessLogConfig) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	savedLogger, savedConf := accessLogger, accessLogConf.Load()
+	accessLogger = zap.New(core)
+	accessLogConf.Store(&cfg)
+	t.Cleanup(func() {
+		accessLogger = savedLogger
+		accessLogConf.Store(savedConf)
+	})
+	return logs
+}
+
+// newAccessLogEngine 创建注册了 TraceContext 及请求日志的引擎
+func newAccessLogEngine(middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(TraceContext(), HandleAppAccessLog())
+	engine.Use(middlewares...)
+	engine.POST("/echo", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "application/json", body)
+	})
+	engine.GET("/healthz", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	engine.GET("/slow", func(c *gin.Context) {
+		time.Sleep(20 * time.Millisecond)
+		c.Status(http.StatusAccepted)
+	})
+	return engine
+}
+
+func TestAccessLog(t *testing.T) {
+	cfg := defaultAccessLogConfig()
+	cfg.RequestBody = true
+	cfg.ResponseBody = true
+	logs := observeAccessLogs(t, cfg)
+	engine := newAccessLogEngine()
+
+	body := `{"password":"p","phone":"13812345678"}`
+	req := httptest.NewRequest(http.MethodPost, "/echo?token=abc&page=1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	// 记录请求内容后，后续处理仍能读取完整的请求内容
+	if w.Body.String() != body {
+		t.Fatalf("response = %s, want %s", w.Body.String(), body)
+	}
+
+	entries := logs.TakeAll()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	m := e.ContextMap()
+	if e.Message != "POST /echo" || e.Level != zapcore.InfoLevel {
+		t.Fatalf("entry = %s %q", e.Level, e.Message)
+	}
+	masked := `{"password":"******","phone":"138****5678"}`
+	if m["x_status"] != int64(200) || m["x_method"] != "POST" || m["x_query"] != "page=1&token=******" {
+		t.Errorf("fields = %v", m)
+	}
+	if m["x_request_size"] != int64(len(body)) || m["x_response_size"] != int64(len(body)) {
+		t.Errorf("request size = %v, response size = %v", m["x_request_size"], m["x_response_size"])
+	}
+	if m["x_request_body"] != masked || m["x_response_body"] != masked {
+		t.Errorf("request body = %v, response body = %v", m["x_request_body"], m["x_response_body"])
+	}
+}
+
+func TestAccessLogChunkedRequestSize(t *testing.T) {
+	logs := observeAccessLogs(t, defaultAccessLogConfig())
+	engine := newAccessLogEngine()
+
+	body := strings.Repeat("a", 1000)
+	req := httptest.NewRequest(http.MethodPost, "/echo", io.MultiReader(strings.NewReader(body)))
+	req.ContentLength = -1
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	m := logs.TakeAll()[0].ContextMap()
+	if m["x_request_size"] != int64(len(body)) {
+		t.Fatalf("x_request_size = %v, want %d", m["x_request_size"], len(body))
+	}
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if m = logs.TakeAll()[0].ContextMap(); m["x_request_size"] != int64(0) {
+		t.Fatalf("x_request_size = %v, want 0 without body", m["x_request_size"])
+	}
+}
+
+func TestAccessLogInstalledTwice(t *testing.T) {
+	logs := observeAccessLogs(t, defaultAccessLogConfig())
+	engine := newAccessLogEngine(HandleAppAccessLog())
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if n := logs.Len(); n != 1 {
+		t.Fatalf("logged %d entries, want 1", n)
+	}
+}
+
+func TestAccessLogConcurrentTraceRead(t *testing.T) {
+	observeLogs(t)
+	logs := observeAccessLogs(t, defaultAccessLogConfig())
+	var wg sync.WaitGroup
+	engine := newAccessLogEngine(func(c *gin.Context) {
+		// 请求处理中启动的协程在请求结束后继续使用 Trace，go test -race 时检查数据竞争
+		ctx := c.Request.Context()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				Info(ctx, "background")
+			}
+		}()
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	wg.Wait()
+	if m := logs.TakeAll()[0].ContextMap(); m["x_status"] != int64(http.StatusAccepted) {
+		t.Fatalf("x_status = %v", m["x_status"])
+	}
+}
+
+func TestAccessLogSkipAndSlow(t *testing.T) {
+	cfg := defaultAccessLogConfig()
+	cfg.SlowThreshold = 10 * time.Millisecond
+	logs := observeAccessLogs(t, cfg)
+	engine := newAccessLogEngine()
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if n := logs.Len(); n != 0 {
+		t.Fatalf("logged %d entries for skipped path, want 0", n)
+	}
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	entries := logs.TakeAll()
+	if len(entries) != 1 || entries[0].Level != zapcore.WarnLevel || entries[0].Message != "slow request GET /slow" {
+		t.Fatalf("entries = %v, want slow request warning", entries)
+	}
+
+	cfg.Enable = false
+	accessLogConf.Store(&cfg)
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if n := logs.Len(); n != 0 {
+		t.Fatalf("logged %d entries when disabled, want 0", n)
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	patterns := []string{"/healthz", "/static/*"}
+	for path, want := range map[string]bool{
+		"/healthz":      true,
+		"/healthz/live": false,
+		"/static/a.js":  true,
+		"/static":       false,
+		"/orders":       false,
+	} {
+		if got := matchPath(patterns, path); got != want {
+			t.Errorf("matchPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package gohera
+
+import (
+	"expvar"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-contrib/pprof"
+	"github.com/gin-gonic/gin"
+)
+
+type adminRoute struct {
+	method   string
+	path     string
+	handlers []gin.HandlerFunc
+}
+
+var (
+	adminRoutes []adminRoute
+	adminMu     sync.Mutex
+)
+
+// AdminEnabled 是否开启独立的运维端口 (admin.port)
+func AdminEnabled() bool {
+	return GetInt("admin.port") != 0
+}
+
+// RegisterAdminRoute 在运维端口上注册接口
+// 需要在 StartupService 之前调用，未开启运维端口时不会生效
+func RegisterAdminRoute(method, path string, handlers ...gin.HandlerFunc) {
+	adminMu.Lock()
+	defer adminMu.Unlock()
+	adminRoutes = append(adminRoutes, adminRoute{method: method, path: path, handlers: handlers})
+}
+
+// newAdminEngine 创建运维端口的 Gin 引擎
+// 默认注册健康检查、运行指标、脱敏后的生效配置、日志级别和 pprof (admin.pprof = false 时关闭)
+func newAdminEngine() *gin.Engine {
+	engine := gin.New()
+	engine.Use(HandlerRecovery(false))
+	registerHealthRouter(engine)
+	engine.GET("/metrics", gin.WrapH(expvar.Handler()))
+	engine.GET("/config", configDumpHandler)
+	engine.GET("/log/level", logLevelHandler)
+	engine.PUT("/log/level", setLogLevelHandler)
+	if !IsSet("admin.pprof") || GetBool("admin.pprof") {
+		pprof.Register(engine)
+	}
+
+	adminMu.Lock()
+	defer adminMu.Unlock()
+	for _, r := range adminRoutes {
+		engine.Handle(r.method, r.path, r.handlers...)
+	}
+	return engine
+}
+
+// newAdminServer 根据 admin.host/admin.port 创建运维服务，未开启时返回 nil
+func newAdminServer() *http.Server {
+	if !AdminEnabled() {
+		return nil
+	}
+	return &http.Server{
+		Addr:    GetString("admin.host") + ":" + strconv.Itoa(GetInt("admin.port")),
+		Handler: newAdminEngine(),
+	}
+}
@@ -0,0 +1,68 @@
+package gohera
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminServerDisabled(t *testing.T) {
+	loadTestConfig(t, "", map[string]string{"app.toml": "[http]\nport = 8080\n"})
+	if AdminEnabled() {
+		t.Fatal("AdminEnabled() = true without admin.port")
+	}
+	if srv := newAdminServer(); srv != nil {
+		t.Fatalf("newAdminServer() = %v, want nil", srv)
+	}
+}
+
+func TestAdminServerRoutes(t *testing.T) {
+	loadTestConfig(t, "", map[string]string{"app.toml": "[http]\nport = 8080\n[admin]\nhost = \"127.0.0.1\"\nport = 9090\npprof = false\n"})
+	adminMu.Lock()
+	saved := adminRoutes
+	adminMu.Unlock()
+	t.Cleanup(func() {
+		adminMu.Lock()
+		adminRoutes = saved
+		adminMu.Unlock()
+	})
+	RegisterAdminRoute(http.MethodGet, "/custom", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	srv := newAdminServer()
+	if srv == nil {
+		t.Fatal("newAdminServer() = nil with admin.port")
+	}
+	if srv.Addr != "127.0.0.1:9090" {
+		t.Fatalf("Addr = %s, want 127.0.0.1:9090", srv.Addr)
+	}
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/healthz", http.StatusOK},
+		{"/healthz/live", http.StatusOK},
+		{"/metrics", http.StatusOK},
+		{"/custom", http.StatusOK},
+		{"/debug/pprof/", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.code {
+			t.Errorf("GET %s = %d, want %d", tt.path, w.Code, tt.code)
+		}
+	}
+}
+
+func TestAdminServerPprof(t *testing.T) {
+	loadTestConfig(t, "", map[string]string{"app.toml": "[http]\nport = 8080\n[admin]\nport = 9090\n"})
+	w := httptest.NewRecorder()
+	newAdminServer().Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /debug/pprof/ = %d, want 200", w.Code)
+	}
+}
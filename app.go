@@ -0,0 +1,312 @@
+package gohera
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/metlive/gohera/mysql"
+	"github.com/metlive/gohera/redis"
+	"go.uber.org/zap"
+)
+
+// App 应用实例
+// Engine 在使用 WithoutEngine 时为 nil，适用于 CLI、Worker 等无需 HTTP 服务的场景
+type App struct {
+	Engine *gin.Engine
+}
+
+// Option 应用启动选项
+type Option func(*options)
+
+type options struct {
+	env           string
+	configName    string
+	configPaths   []string
+	providers     []*configProviderEntry
+	skipConfig    bool
+	logger        *zap.Logger
+	skipLogger    bool
+	slogDefault   bool
+	slogOutput    slog.Handler
+	mysql         map[string]*mysql.DB
+	skipMysql     bool
+	redis         *redis.Client
+	skipRedis     bool
+	skipTrace     bool
+	skipJWT       bool
+	skipEngine    bool
+	skipRoutes    bool
+	middlewares   []gin.HandlerFunc
+	setMiddleware bool
+	validator     binding.StructValidator
+	setValidator  bool
+}
+
+// WithEnv 指定运行环境，优先级高于 -env 启动参数
+func WithEnv(env string) Option {
+	return func(o *options) {
+		o.env = env
+	}
+}
+
+// WithConfigName 指定配置文件名（不含扩展名），默认为 app
+func WithConfigName(name string) Option {
+	return func(o *options) {
+		o.configName = name
+	}
+}
+
+// WithConfigPaths 指定配置文件的查找目录，默认为 ./、./config、./configs
+func WithConfigPaths(paths ...string) Option {
+	return func(o *options) {
+		o.configPaths = paths
+	}
+}
+
+// WithConfigProvider 添加配置源，与本地配置文件按 priority 合并，详见 AddConfigProvider
+func WithConfigProvider(p ConfigProvider, priority int) Option {
+	return func(o *options) {
+		o.providers = append(o.providers, &configProviderEntry{provider: p, priority: priority})
+	}
+}
+
+// WithoutConfig 跳过配置文件加载
+func WithoutConfig() Option {
+	return func(o *options) {
+		o.skipConfig = true
+	}
+}
+
+// WithLogger 使用自定义的 zap.Logger 替换框架默认的日志处理器
+func WithLogger(l *zap.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithoutLogger 跳过日志初始化，日志将被丢弃
+func WithoutLogger() Option {
+	return func(o *options) {
+		o.skipLogger = true
+	}
+}
+
+// WithSlogDefault 日志初始化后通过 slog.SetDefault 将 slog 的默认输出替换为框架日志
+// 第三方库通过 log/slog 输出的日志会写入框架的日志文件，并带上 x_trace_id 等 Trace 信息
+func WithSlogDefault() Option {
+	return func(o *options) {
+		o.slogDefault = true
+	}
+}
+
+// WithSlogOutput 将 Info/Error 等日志函数的输出转发到自定义的 slog.Handler
+func WithSlogOutput(h slog.Handler) Option {
+	return func(o *options) {
+		o.slogOutput = h
+	}
+}
+
+// WithMysql 使用已创建的连接替换配置中的 Mysql 初始化，Key 为数据库名
+func WithMysql(dbs map[string]*mysql.DB) Option {
+	return func(o *options) {
+		o.mysql = dbs
+	}
+}
+
+// WithoutMysql 跳过 Mysql 初始化
+func WithoutMysql() Option {
+	return func(o *options) {
+		o.skipMysql = true
+	}
+}
+
+// WithRedis 使用已创建的客户端替换配置中的 Redis 初始化
+func WithRedis(client *redis.Client) Option {
+	return func(o *options) {
+		o.redis = client
+	}
+}
+
+// WithoutRedis 跳过 Redis 初始化
+func WithoutRedis() Option {
+	return func(o *options) {
+		o.skipRedis = true
+	}
+}
+
+// WithoutTrace 跳过链路追踪初始化，不导出 Span，适用于 CLI 等离线工具
+func WithoutTrace() Option {
+	return func(o *options) {
+		o.skipTrace = true
+	}
+}
+
+// WithoutJWT 跳过 JWT 验证初始化，不加载密钥及 JWKS，JWTAuth 中间件将拒绝所有请求
+func WithoutJWT() Option {
+	return func(o *options) {
+		o.skipJWT = true
+	}
+}
+
+// WithoutEngine 不创建 Gin 引擎
+func WithoutEngine() Option {
+	return func(o *options) {
+		o.skipEngine = true
+	}
+}
+
+// WithoutDefaultRoutes 不注册默认路由 (Healthz, 404, 405)
+func WithoutDefaultRoutes() Option {
+	return func(o *options) {
+		o.skipRoutes = true
+	}
+}
+
+// WithMiddleware 替换默认的中间件 (TraceContext, HandlerRecovery)
+// 不传参数时不注册任何中间件
+func WithMiddleware(middlewares ...gin.HandlerFunc) Option {
+	return func(o *options) {
+		o.middlewares = middlewares
+		o.setMiddleware = true
+	}
+}
+
+// WithValidator 替换默认的参数验证器，传入 nil 时保留 Gin 自带的验证器
+func WithValidator(v binding.StructValidator) Option {
+	return func(o *options) {
+		o.validator = v
+		o.setValidator = true
+	}
+}
+
+// New 创建应用实例
+// 依次初始化环境变量、配置文件、日志、数据库（MySQL/Redis）和 Gin 引擎，任一环节失败都会返回错误
+func New(opts ...Option) (*App, error) {
+	o := &options{
+		configName:  defaultConfigName,
+		configPaths: defaultConfigPaths,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.env == "" {
+		if !flag.Parsed() {
+			flag.Parse()
+		}
+		o.env = *env
+	}
+
+	// 解析环境变量
+	if err := parseEnv(o.env); err != nil {
+		return nil, fmt.Errorf("env parse fail: %w", err)
+	}
+
+	// 初始化应用配置，并验证框架及业务注册的配置结构体
+	if !o.skipConfig {
+		if !o.skipEngine {
+			RegisterConfigSchema("http", httpConfig{})
+		}
+		if !o.skipLogger && o.logger == nil {
+			RegisterConfigSchema("log", loggerConfig{})
+		}
+		if !o.skipMysql && o.mysql == nil {
+			RegisterConfigSchema("mysql.*", mysql.Config{})
+		}
+		if !o.skipTrace {
+			RegisterConfigSchema("trace", traceConfig{})
+		}
+		if !o.skipJWT {
+			RegisterConfigSchema("jwt", jwtConfig{})
+		}
+		for _, p := range o.providers {
+			AddConfigProvider(p.provider, p.priority)
+		}
+		if err := loadAppConfig(o.configName, o.configPaths, appEnv); err != nil {
+			return nil, fmt.Errorf("init config fail: %w", err)
+		}
+		if err := ValidateConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	// 初始化日志处理器
+	switch {
+	case o.logger != nil:
+		logger = o.logger
+		accessLogger = o.logger
+	case o.skipLogger:
+		logger = zap.NewNop()
+		accessLogger = zap.NewNop()
+	default:
+		if err := initLogger(); err != nil {
+			return nil, err
+		}
+	}
+	if o.slogOutput != nil {
+		SetSlogOutput(o.slogOutput)
+	}
+	if o.slogDefault {
+		slog.SetDefault(slog.New(NewSlogHandler()))
+	}
+
+	// 初始化链路追踪及 JWT 验证
+	if !o.skipConfig && !o.skipTrace {
+		if err := initTrace(); err != nil {
+			return nil, fmt.Errorf("init trace fail: %w", err)
+		}
+	}
+	if !o.skipConfig && !o.skipJWT {
+		if err := initJWT(); err != nil {
+			return nil, fmt.Errorf("init jwt fail: %w", err)
+		}
+	}
+
+	// mysql初始化
+	switch {
+	case o.mysql != nil:
+		for key, db := range o.mysql {
+			Mysql[key] = db
+		}
+	case !o.skipMysql:
+		if err := initMysql(); err != nil {
+			return nil, err
+		}
+	}
+
+	// redis初始化
+	switch {
+	case o.redis != nil:
+		Redis = o.redis
+	case !o.skipRedis:
+		if err := initRedis(); err != nil {
+			return nil, err
+		}
+	}
+
+	app := &App{}
+	if !o.skipEngine {
+		app.Engine = newEngine(o)
+	}
+	return app, nil
+}
+
+// Run 启动 HTTP 服务并阻塞至服务退出
+func (a *App) Run() error {
+	if a.Engine == nil {
+		return errors.New("gohera: engine is disabled")
+	}
+	StartupService(a.Engine)
+	return nil
+}
+
+// Shutdown 执行退出钩子，释放应用持有的资源
+// 通过 Run 启动的服务会在退出时自动执行，CLI、Worker 等场景需要手动调用
+func (a *App) Shutdown(ctx context.Context) error {
+	return Shutdown(ctx)
+}
@@ -0,0 +1,143 @@
+package gohera
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestConfig 在临时目录中写入配置文件，返回目录路径
+func writeTestConfig(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// loadTestConfig 写入配置文件并以 env 环境加载，测试结束后停止文件监听
+func loadTestConfig(t *testing.T, env string, files map[string]string) string {
+	t.Helper()
+	dir := writeTestConfig(t, files)
+	if err := loadAppConfig("app", []string{dir}, env); err != nil {
+		t.Fatalf("loadAppConfig() error = %v", err)
+	}
+	t.Cleanup(func() {
+		configReloadMu.Lock()
+		defer configReloadMu.Unlock()
+		if configFileCancel != nil {
+			configFileCancel()
+			configFileCancel = nil
+		}
+	})
+	return dir
+}
+
+// bareOptions 跳过所有需要外部依赖的初始化
+func bareOptions(opts ...Option) []Option {
+	return append([]Option{WithEnv(DeployEnvTest), WithoutConfig(), WithoutLogger(), WithoutMysql(), WithoutRedis()}, opts...)
+}
+
+func TestNewInvalidEnv(t *testing.T) {
+	_, err := New(WithEnv("staging"), WithoutConfig(), WithoutLogger())
+	if err == nil || !strings.Contains(err.Error(), "invalid environment: staging") {
+		t.Fatalf("New() error = %v, want invalid environment", err)
+	}
+}
+
+func TestNewWithoutEngine(t *testing.T) {
+	app, err := New(bareOptions(WithoutEngine())...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if app.Engine != nil {
+		t.Fatal("Engine should be nil when WithoutEngine is used")
+	}
+	if err = app.Run(); err == nil {
+		t.Fatal("Run() should fail without engine")
+	}
+}
+
+func TestNewEngineOptions(t *testing.T) {
+	app, err := New(bareOptions()...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if n := len(app.Engine.Handlers); n == 0 {
+		t.Fatal("default middlewares are not registered")
+	}
+	routes := make(map[string]bool)
+	for _, r := range app.Engine.Routes() {
+		routes[r.Path] = true
+	}
+	if !routes["/healthz"] {
+		t.Fatal("default routes are not registered")
+	}
+
+	app, err = New(bareOptions(WithMiddleware(), WithoutDefaultRoutes())...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if n := len(app.Engine.Handlers); n != 0 {
+		t.Fatalf("middlewares = %d, want 0", n)
+	}
+	if n := len(app.Engine.Routes()); n != 0 {
+		t.Fatalf("routes = %d, want 0", n)
+	}
+}
+
+func TestNewConfigError(t *testing.T) {
+	resetConfigSchemas(t)
+	dir := writeTestConfig(t, map[string]string{"app.toml": "[http\nport = 8080\n"})
+	_, err := New(WithEnv(DeployEnvTest), WithConfigPaths(dir), WithoutLogger(), WithoutMysql(), WithoutRedis())
+	if err == nil || !strings.Contains(err.Error(), "init config fail") {
+		t.Fatalf("New() error = %v, want init config fail", err)
+	}
+}
+
+func TestNewWithoutTraceAndJWT(t *testing.T) {
+	resetConfigSchemas(t)
+	resetConfigListeners(t)
+	savedJWT, savedExporter := jwtState.Load(), traceExporter.Load()
+	t.Cleanup(func() {
+		jwtState.Store(savedJWT)
+		traceExporter.Store(savedExporter)
+	})
+	dir := writeTestConfig(t, map[string]string{"app.toml": `
+[trace.export]
+enable = true
+endpoint = "http://127.0.0.1:1"
+[jwt]
+jwks = "https://127.0.0.1:1/jwks.json"
+`})
+	opts := []Option{WithEnv(DeployEnvTest), WithConfigPaths(dir), WithoutLogger(), WithoutMysql(), WithoutRedis(), WithoutEngine()}
+	t.Cleanup(func() {
+		configReloadMu.Lock()
+		defer configReloadMu.Unlock()
+		if configFileCancel != nil {
+			configFileCancel()
+			configFileCancel = nil
+		}
+	})
+
+	// 离线时加载 JWKS 失败
+	if _, err := New(opts...); err == nil || !strings.Contains(err.Error(), "init jwt fail") {
+		t.Fatalf("New() error = %v, want init jwt fail", err)
+	}
+	traceExporter.Swap(nil).close()
+
+	if _, err := New(append(opts, WithoutTrace(), WithoutJWT())...); err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if jwtState.Load() != nil || traceExporter.Load() != nil {
+		t.Fatal("trace and jwt should not be initialized")
+	}
+}
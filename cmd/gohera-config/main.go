@@ -0,0 +1,42 @@
+// gohera-config 输出分层合并后的生效配置，敏感配置项自动脱敏
+//
+//	gohera-config -env prod -path ./configs -source
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/metlive/gohera"
+)
+
+var (
+	paths  = flag.String("path", "./,./config,./configs", "config search paths, separated by comma")
+	name   = flag.String("name", "app", "config file name without extension")
+	source = flag.Bool("source", false, "annotate each key with the source of its value")
+)
+
+func main() {
+	flag.Parse()
+
+	_, err := gohera.New(
+		gohera.WithConfigName(*name),
+		gohera.WithConfigPaths(strings.Split(*paths, ",")...),
+		gohera.WithoutLogger(),
+		gohera.WithoutMysql(),
+		gohera.WithoutRedis(),
+		gohera.WithoutTrace(),
+		gohera.WithoutJWT(),
+		gohera.WithoutEngine(),
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err = gohera.DumpConfig(os.Stdout, *source); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,69 @@
+// gohera-secret 加密/解密配置文件中的敏感信息
+//
+//	APP_CONFIG_KEY=xxx gohera-secret "password"
+//	APP_CONFIG_KEY=xxx gohera-secret -d "ENC(...)"
+//	echo "password" | gohera-secret -key-file /etc/app/config.key
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/metlive/gohera/secret"
+)
+
+var (
+	decrypt = flag.Bool("d", false, "decrypt value instead of encrypt")
+	keyFile = flag.String("key-file", "", "key file path, default read from "+secret.KeyEnv+" or "+secret.KeyFileEnv)
+)
+
+func main() {
+	flag.Parse()
+
+	key, err := loadKey()
+	if err != nil {
+		fail(err)
+	}
+
+	values := flag.Args()
+	if len(values) == 0 {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				values = append(values, line)
+			}
+		}
+	}
+
+	for _, value := range values {
+		var out string
+		if *decrypt {
+			out, err = secret.Decrypt(key, value)
+		} else {
+			out, err = secret.Encrypt(key, value)
+		}
+		if err != nil {
+			fail(err)
+		}
+		fmt.Println(out)
+	}
+}
+
+func loadKey() ([]byte, error) {
+	if *keyFile != "" {
+		data, err := os.ReadFile(*keyFile)
+		if err != nil {
+			return nil, err
+		}
+		return secret.ParseKey(string(data)), nil
+	}
+	return secret.LoadKey()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -1,23 +1,51 @@
 package gohera
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 )
 
-var config = viper.New()
+// configViper 当前使用的配置，热更新时整体替换，读取时通过 currentConfig 获取
+var configViper atomic.Pointer[viper.Viper]
+
+// emptyConfig 加载配置前使用的空配置
+var emptyConfig = viper.New()
 var configLoaded bool
 var configCache atomic.Pointer[map[string]any]
+var configSources atomic.Pointer[map[string]string]
+var configSecrets atomic.Pointer[map[string]bool]
+
+// 当前使用的本地文件配置源，热更新时按相同的配置源重新加载
+var (
+	configFile       *configProviderEntry
+	configFileKey    string
+	configFileCancel context.CancelFunc
+	configLoadedSeq  int
+	configReloadMu   sync.Mutex
+)
+
+// configWatchCtx 所有配置源的监听在服务退出时停止
+var configWatchCtx, configWatchCancel = context.WithCancel(context.Background())
+
+const defaultConfigName = "app"
+
+var defaultConfigPaths = []string{"./", "./config", "./configs"}
 
 func init() {
+	OnShutdown("config", func(ctx context.Context) error {
+		configWatchCancel()
+		return nil
+	})
 	// 尝试在包加载时初始化配置，以便包级别的变量初始化可以获取到配置
 	_ = initAppConfig()
 }
@@ -25,38 +53,128 @@ func init() {
 // initAppConfig 初始化应用配置
 // 按照优先级从当前目录、./config、./configs 加载 app.toml/yaml/json 等配置文件
 func initAppConfig() error {
-	if configLoaded {
+	return loadAppConfig(defaultConfigName, defaultConfigPaths, appEnv)
+}
+
+// loadAppConfig 按指定的文件名、目录和环境分层加载配置，并合并通过 AddConfigProvider 添加的配置源
+// 参数及配置源均未变化时只加载一次，否则重新加载并替换当前配置
+func loadAppConfig(name string, paths []string, env string) error {
+	configReloadMu.Lock()
+	defer configReloadMu.Unlock()
+
+	fileKey := name + ":" + strings.Join(paths, ",") + ":" + env
+	configProviderMu.Lock()
+	seq := configProviderSeq
+	configProviderMu.Unlock()
+	if configLoaded && configFileKey == fileKey && configLoadedSeq == seq {
 		return nil
 	}
 
-	config.SetConfigName("app")
-	config.AddConfigPath("./")
-	config.AddConfigPath("./config")
-	config.AddConfigPath("./configs")
-
-	err := config.ReadInConfig()
+	file := configFile
+	if file == nil || configFileKey != fileKey {
+		file = &configProviderEntry{provider: NewFileConfigProvider(name, paths, env)}
+	}
+	b, err := buildConfig(context.Background(), configEntries(file))
 	if err != nil {
 		return err
 	}
+	// 替换本地文件配置源时停止旧的文件监听
+	if file != configFile && configFileCancel != nil {
+		configFileCancel()
+		configFileCancel = nil
+	}
+	configFile, configFileKey, configLoadedSeq = file, fileKey, seq
+	applyConfigBuild(b)
+	configLoaded = true
+	return startConfigWatch()
+}
 
-	config.SetEnvPrefix("APP")
-	config.AutomaticEnv()
-	config.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+// reloadAppConfig 配置源变更时重新加载，加载或验证失败时保留当前配置
+func reloadAppConfig() {
+	configReloadMu.Lock()
+	defer configReloadMu.Unlock()
 
-	config.OnConfigChange(func(e fsnotify.Event) {
-		fmt.Printf("Config file changed: %s\n", e.Name)
-		_ = refreshCache()
-	})
-	config.WatchConfig()
+	b, err := buildConfig(configWatchCtx, configEntries(configFile))
+	if err != nil {
+		fmt.Printf("Config reload fail: %s\n", err)
+		return
+	}
+	if err = validateConfig(b.v); err != nil {
+		fmt.Printf("Config reload rejected: %s\n", err)
+		return
+	}
+	applyConfigBuild(b)
+}
 
-	_ = refreshCache()
-	configLoaded = true
+// configEntries 返回本地文件配置源及所有已添加的配置源
+func configEntries(file *configProviderEntry) []*configProviderEntry {
+	configProviderMu.Lock()
+	defer configProviderMu.Unlock()
+	entries := make([]*configProviderEntry, 0, len(configProviders)+1)
+	if file != nil {
+		entries = append(entries, file)
+	}
+	return append(entries, configProviders...)
+}
+
+// startConfigWatch 为尚未监听的配置源开始监听
+func startConfigWatch() error {
+	for _, e := range configEntries(configFile) {
+		if e.watching {
+			continue
+		}
+		ctx := configWatchCtx
+		if e == configFile {
+			ctx, configFileCancel = context.WithCancel(configWatchCtx)
+		}
+		if err := e.provider.Watch(ctx, reloadAppConfig); err != nil {
+			return fmt.Errorf("watch config %s: %w", e.provider.Name(), err)
+		}
+		e.watching = true
+	}
 	return nil
 }
 
+// applyConfigBuild 使用新的加载结果替换当前配置
+func applyConfigBuild(b *configBuild) {
+	old := configCache.Load()
+	oldSecrets := loadConfigSecrets()
+	configViper.Store(b.v)
+	configSources.Store(&b.sources)
+	configSecrets.Store(&b.secrets)
+	_ = refreshCache()
+	if old != nil {
+		cur := *configCache.Load()
+		// 变更前后任一方解密自 ENC(...) 的配置项均脱敏
+		secrets := maps.Clone(b.secrets)
+		maps.Copy(secrets, oldSecrets)
+		for _, change := range diffConfig(newConfigSnapshot(*old), newConfigSnapshot(cur), secrets) {
+			Infotf(context.Background(), "config changed: %s %v -> %v", change.Key, change.Old, change.New)
+		}
+		notifyConfigChange(*old, cur)
+	}
+}
+
+// currentConfig 获取当前使用的配置，未加载时返回空配置
+func currentConfig() *viper.Viper {
+	if v := configViper.Load(); v != nil {
+		return v
+	}
+	return emptyConfig
+}
+
+// ConfigSource 获取配置项生效值的来源
+// 返回配置文件的绝对路径、配置源名称，或 env:APP_XXX 表示来自环境变量，未找到时返回空字符串
+func ConfigSource(key string) string {
+	if sources := configSources.Load(); sources != nil {
+		return (*sources)[strings.ToLower(key)]
+	}
+	return ""
+}
+
 // refreshCache 刷新配置缓存，将所有配置扁平化存入 atomic.Pointer
 func refreshCache() error {
-	allSettings := config.AllSettings()
+	allSettings := currentConfig().AllSettings()
 	flatCache := make(map[string]any)
 	flattenSettings("", allSettings, flatCache)
 	configCache.Store(&flatCache)
@@ -85,7 +203,7 @@ func GetConfig(key string) any {
 			return v
 		}
 	}
-	return config.Get(key)
+	return currentConfig().Get(key)
 }
 
 // GetDefaultString 获取字符串配置，如果不存在则返回默认值
@@ -103,7 +221,7 @@ func GetString(key string) string {
 			return cast.ToString(v)
 		}
 	}
-	return config.GetString(key)
+	return currentConfig().GetString(key)
 }
 
 // GetBool 获取布尔值配置
@@ -113,7 +231,7 @@ func GetBool(key string) bool {
 			return cast.ToBool(v)
 		}
 	}
-	return config.GetBool(key)
+	return currentConfig().GetBool(key)
 }
 
 // GetInt 获取 int 配置
@@ -123,7 +241,7 @@ func GetInt(key string) int {
 			return cast.ToInt(v)
 		}
 	}
-	return config.GetInt(key)
+	return currentConfig().GetInt(key)
 }
 
 // GetInt32 获取 int32 配置
@@ -133,7 +251,7 @@ func GetInt32(key string) int32 {
 			return cast.ToInt32(v)
 		}
 	}
-	return config.GetInt32(key)
+	return currentConfig().GetInt32(key)
 }
 
 // GetInt64 获取 int64 配置
@@ -143,7 +261,7 @@ func GetInt64(key string) int64 {
 			return cast.ToInt64(v)
 		}
 	}
-	return config.GetInt64(key)
+	return currentConfig().GetInt64(key)
 }
 
 // GetUint 获取 uint 配置
@@ -153,7 +271,7 @@ func GetUint(key string) uint {
 			return cast.ToUint(v)
 		}
 	}
-	return config.GetUint(key)
+	return currentConfig().GetUint(key)
 }
 
 // GetUint32 获取 uint32 配置
@@ -163,7 +281,7 @@ func GetUint32(key string) uint32 {
 			return cast.ToUint32(v)
 		}
 	}
-	return config.GetUint32(key)
+	return currentConfig().GetUint32(key)
 }
 
 // GetUint64 获取 uint64 配置
@@ -173,7 +291,7 @@ func GetUint64(key string) uint64 {
 			return cast.ToUint64(v)
 		}
 	}
-	return config.GetUint64(key)
+	return currentConfig().GetUint64(key)
 }
 
 // GetFloat64 获取 float64 配置
@@ -183,7 +301,7 @@ func GetFloat64(key string) float64 {
 			return cast.ToFloat64(v)
 		}
 	}
-	return config.GetFloat64(key)
+	return currentConfig().GetFloat64(key)
 }
 
 // GetTime 获取时间配置
@@ -193,7 +311,7 @@ func GetTime(key string) time.Time {
 			return cast.ToTime(v)
 		}
 	}
-	return config.GetTime(key)
+	return currentConfig().GetTime(key)
 }
 
 // GetDuration 获取时间间隔配置
@@ -203,7 +321,7 @@ func GetDuration(key string) time.Duration {
 			return cast.ToDuration(v)
 		}
 	}
-	return config.GetDuration(key)
+	return currentConfig().GetDuration(key)
 }
 
 // GetStringSlice 获取字符串切片配置
@@ -213,7 +331,7 @@ func GetStringSlice(key string) []string {
 			return cast.ToStringSlice(v)
 		}
 	}
-	return config.GetStringSlice(key)
+	return currentConfig().GetStringSlice(key)
 }
 
 // GetStringMap 获取 map[string]any 配置
@@ -223,7 +341,7 @@ func GetStringMap(key string) map[string]any {
 			return cast.ToStringMap(v)
 		}
 	}
-	return config.GetStringMap(key)
+	return currentConfig().GetStringMap(key)
 }
 
 // GetStringMapString 获取 map[string]string 配置
@@ -233,7 +351,7 @@ func GetStringMapString(key string) map[string]string {
 			return cast.ToStringMapString(v)
 		}
 	}
-	return config.GetStringMapString(key)
+	return currentConfig().GetStringMapString(key)
 }
 
 // GetStringMapStringSlice 获取 map[string][]string 配置
@@ -243,7 +361,7 @@ func GetStringMapStringSlice(key string) map[string][]string {
 			return cast.ToStringMapStringSlice(v)
 		}
 	}
-	return config.GetStringMapStringSlice(key)
+	return currentConfig().GetStringMapStringSlice(key)
 }
 
 // IsSet 检查配置项是否存在
@@ -253,7 +371,7 @@ func IsSet(key string) bool {
 			return true
 		}
 	}
-	return config.IsSet(key)
+	return currentConfig().IsSet(key)
 }
 
 // UnmarshalKey 将配置反序列化到结构体
@@ -262,5 +380,5 @@ func UnmarshalKey(key string, rawVal any) error {
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return errors.New("rawVal must be a non-nil pointer")
 	}
-	return config.UnmarshalKey(key, rawVal)
+	return currentConfig().UnmarshalKey(key, rawVal)
 }
@@ -0,0 +1,173 @@
+package gohera
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RedactedValue 脱敏后的配置值
+const RedactedValue = "******"
+
+// configRedactPattern 需要脱敏的配置项
+var configRedactPattern = regexp.MustCompile(`(?i)(password|passwd|pwd|secret|token|auth|credential|private_key|access_key)`)
+
+// ConfigSnapshot 扁平化的配置快照，仅包含叶子配置项
+type ConfigSnapshot map[string]any
+
+// ConfigChange 配置项变更，敏感配置项的值已脱敏
+type ConfigChange struct {
+	Key string `json:"key"`
+	Old any    `json:"old"`
+	New any    `json:"new"`
+}
+
+// SnapshotConfig 获取当前生效配置的快照
+// 快照中的值未脱敏，输出时请使用 DumpConfig 或 RedactConfigValue
+func SnapshotConfig() ConfigSnapshot {
+	cache := configCache.Load()
+	if cache == nil {
+		return ConfigSnapshot{}
+	}
+	return newConfigSnapshot(*cache)
+}
+
+// newConfigSnapshot 从扁平化配置缓存中提取叶子配置项
+func newConfigSnapshot(cache map[string]any) ConfigSnapshot {
+	snapshot := make(ConfigSnapshot, len(cache))
+	for k, v := range cache {
+		if _, ok := v.(map[string]any); ok {
+			continue
+		}
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
+// loadConfigSecrets 获取当前配置中解密自 ENC(...) 的配置项
+func loadConfigSecrets() map[string]bool {
+	if secrets := configSecrets.Load(); secrets != nil {
+		return *secrets
+	}
+	return nil
+}
+
+// RedactConfigValue 对敏感配置项（password/secret/token/auth 等）及解密自 ENC(...) 的配置项的值进行脱敏
+func RedactConfigValue(key string, value any) any {
+	return redactConfigValue(key, value, loadConfigSecrets())
+}
+
+// redactConfigValue 按配置项名称及 secrets 中记录的配置项脱敏
+func redactConfigValue(key string, value any, secrets map[string]bool) any {
+	if value != nil && (configRedactPattern.MatchString(key) || secrets[strings.ToLower(key)]) {
+		return RedactedValue
+	}
+	return redactNestedValue(value)
+}
+
+// configQueryRedactPattern URL 等字符串中名称敏感的 query 参数
+var configQueryRedactPattern = regexp.MustCompile(`([?&][^=&#\s]*(?i:password|passwd|pwd|secret|token|auth|credential|private_key|access_key)[^=&#\s]*=)[^&#\s]*`)
+
+// redactNestedValue 对列表及表中名称敏感的配置项递归脱敏，字符串中敏感的 query 参数同样脱敏
+// 返回脱敏后的副本，不修改原配置
+func redactNestedValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return configQueryRedactPattern.ReplaceAllString(v, "${1}"+RedactedValue)
+	case []string:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = redactNestedValue(item)
+		}
+		return list
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = redactNestedValue(item)
+		}
+		return list
+	case []map[string]any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = redactNestedValue(item)
+		}
+		return list
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[k] = redactConfigValue(k, item, nil)
+		}
+		return m
+	case map[string]string:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[k] = redactConfigValue(k, item, nil)
+		}
+		return m
+	}
+	return value
+}
+
+// DumpConfig 输出当前生效的配置，敏感配置项自动脱敏
+// withSource 为 true 时在每行末尾标注配置值的来源
+func DumpConfig(w io.Writer, withSource ...bool) error {
+	source := len(withSource) > 0 && withSource[0]
+	snapshot := SnapshotConfig()
+	secrets := loadConfigSecrets()
+	for _, key := range slices.Sorted(maps.Keys(snapshot)) {
+		line := fmt.Sprintf("%s = %v", key, redactConfigValue(key, snapshot[key], secrets))
+		if source {
+			if s := ConfigSource(key); s != "" {
+				line += "  # " + s
+			}
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiffConfig 比较两个配置快照，返回按配置项排序的变更列表
+func DiffConfig(old, new ConfigSnapshot) []ConfigChange {
+	return diffConfig(old, new, loadConfigSecrets())
+}
+
+// diffConfig 比较两个配置快照，secrets 中记录的配置项脱敏
+func diffConfig(old, new ConfigSnapshot, secrets map[string]bool) []ConfigChange {
+	keys := make(map[string]struct{}, len(new))
+	for k := range old {
+		keys[k] = struct{}{}
+	}
+	for k := range new {
+		keys[k] = struct{}{}
+	}
+
+	changes := make([]ConfigChange, 0)
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		oldVal, newVal := old[key], new[key]
+		if reflect.DeepEqual(oldVal, newVal) {
+			continue
+		}
+		changes = append(changes, ConfigChange{
+			Key: key,
+			Old: redactConfigValue(key, oldVal, secrets),
+			New: redactConfigValue(key, newVal, secrets),
+		})
+	}
+	return changes
+}
+
+// configDumpHandler 运维接口：输出脱敏后的生效配置，?source=true 时标注来源
+func configDumpHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+	_ = DumpConfig(c.Writer, c.Query("source") == "true")
+}
@@ -0,0 +1,117 @@
+package gohera
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/metlive/gohera/secret"
+)
+
+func TestDumpConfigRedact(t *testing.T) {
+	t.Setenv(secret.KeyEnv, "test-key")
+	enc, err := secret.Encrypt(secret.ParseKey("test-key"), "root:pass@tcp(db)/orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadTestConfig(t, "", map[string]string{"app.toml": `
+[http]
+port = 8080
+[redis]
+password = "plain"
+[mysql.main]
+dsn = "` + enc + `"
+`})
+
+	var buf bytes.Buffer
+	if err := DumpConfig(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"http.port = 8080", "redis.password = " + RedactedValue, "mysql.main.dsn = " + RedactedValue} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DumpConfig() = %q, want to contain %q", out, want)
+		}
+	}
+	if strings.Contains(out, "root:pass") || strings.Contains(out, "plain") {
+		t.Fatalf("DumpConfig() leaks secret: %q", out)
+	}
+	if got := RedactConfigValue("mysql.main.dsn", "x"); got != RedactedValue {
+		t.Fatalf("RedactConfigValue() = %v", got)
+	}
+}
+
+func TestDumpConfigRedactNested(t *testing.T) {
+	loadTestConfig(t, "", map[string]string{"app.toml": `
+[[log.alert.webhooks]]
+name = "ops"
+url = "https://hooks.example.com/send?channel=ops&access_token=tk-123"
+headers = { Authorization = "Bearer abc", Content-Type = "application/json" }
+`})
+
+	var buf bytes.Buffer
+	if err := DumpConfig(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, leak := range []string{"tk-123", "Bearer abc"} {
+		if strings.Contains(out, leak) {
+			t.Fatalf("DumpConfig() leaks %q: %q", leak, out)
+		}
+	}
+	for _, want := range []string{"channel=ops&access_token=" + RedactedValue, "authorization:" + RedactedValue, "application/json", "name:ops"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DumpConfig() = %q, want to contain %q", out, want)
+		}
+	}
+
+	// 变更日志中的列表同样脱敏，且不修改快照中的值
+	old := SnapshotConfig()
+	changes := diffConfig(old, ConfigSnapshot{}, nil)
+	if len(changes) != 1 || strings.Contains(fmt.Sprint(changes[0].Old), "tk-123") || strings.Contains(fmt.Sprint(changes[0].Old), "Bearer abc") {
+		t.Fatalf("diffConfig() = %v", changes)
+	}
+	if !strings.Contains(fmt.Sprint(old["log.alert.webhooks"]), "tk-123") {
+		t.Fatalf("snapshot was modified: %v", old["log.alert.webhooks"])
+	}
+}
+
+func TestDiffConfigRedact(t *testing.T) {
+	old := ConfigSnapshot{"http.port": 8080, "redis.password": "a", "mysql.main.dsn": "old"}
+	cur := ConfigSnapshot{"http.port": 8081, "redis.password": "b", "mysql.main.dsn": "new", "app.name": "demo"}
+	changes := diffConfig(old, cur, map[string]bool{"mysql.main.dsn": true})
+	want := []ConfigChange{
+		{Key: "app.name", Old: nil, New: "demo"},
+		{Key: "http.port", Old: 8080, New: 8081},
+		{Key: "mysql.main.dsn", Old: RedactedValue, New: RedactedValue},
+		{Key: "redis.password", Old: RedactedValue, New: RedactedValue},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("diffConfig() = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("changes[%d] = %v, want %v", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestConfigSecretsReload(t *testing.T) {
+	resetConfigListeners(t)
+	t.Setenv(secret.KeyEnv, "test-key")
+	enc, _ := secret.Encrypt(secret.ParseKey("test-key"), "https://hooks.example.com/abc")
+	dir := loadTestConfig(t, "", map[string]string{"app.toml": "[alert]\nwebhook_url = \"" + enc + "\"\n"})
+	if !loadConfigSecrets()["alert.webhook_url"] {
+		t.Fatal("alert.webhook_url is not recorded as secret")
+	}
+
+	// 热更新后改为明文，不再记录
+	rewriteTestConfig(t, dir, "app.toml", "[alert]\nwebhook_url = \"https://hooks.example.com/def\"\n")
+	if loadConfigSecrets()["alert.webhook_url"] {
+		t.Fatal("alert.webhook_url is still recorded as secret after reload")
+	}
+	if got := RedactConfigValue("alert.webhook_url", "x"); got != "x" {
+		t.Fatalf("RedactConfigValue() = %v, want x", got)
+	}
+}
@@ -0,0 +1,227 @@
+package gohera
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// configIncludeKey 配置文件中用于引入其他文件的配置项
+const configIncludeKey = "includes"
+
+// FileConfigProvider 本地文件配置源
+// 加载顺序：基础配置 app.toml -> 环境配置 app.<env>.toml，后加载的覆盖先加载的
+// 每个文件中的 includes = [...] 会在该文件之后合并，路径相对于当前文件所在目录
+type FileConfigProvider struct {
+	name  string
+	paths []string
+	env   string
+
+	mu      sync.Mutex
+	files   map[string]bool
+	watcher *fsnotify.Watcher
+}
+
+// NewFileConfigProvider 创建本地文件配置源
+// name 为不含扩展名的文件名，paths 为查找目录，env 不为空时合并同目录下的 <name>.<env>.* 文件
+func NewFileConfigProvider(name string, paths []string, env string) *FileConfigProvider {
+	return &FileConfigProvider{
+		name:  name,
+		paths: paths,
+		env:   env,
+	}
+}
+
+// Name 实现 ConfigProvider 接口
+func (p *FileConfigProvider) Name() string {
+	return "file"
+}
+
+// Load 实现 ConfigProvider 接口
+func (p *FileConfigProvider) Load(ctx context.Context) (map[string]any, error) {
+	settings, _, err := p.load()
+	return settings, err
+}
+
+// load 分层加载配置文件，返回合并后的配置及每个配置项所在的文件
+func (p *FileConfigProvider) load() (map[string]any, map[string]string, error) {
+	v := viper.New()
+	v.SetConfigName(p.name)
+	for _, path := range p.paths {
+		v.AddConfigPath(path)
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, nil, err
+	}
+
+	l := &fileLayers{
+		v:       v,
+		sources: make(map[string]string),
+		merged:  make(map[string]bool),
+		stack:   make(map[string]bool),
+	}
+	base, _ := filepath.Abs(v.ConfigFileUsed())
+	l.merged[base] = true
+	l.stack[base] = true
+	l.record(base, v.AllSettings())
+	if err := l.mergeIncludes(base, v.GetStringSlice(configIncludeKey)); err != nil {
+		return nil, nil, err
+	}
+	delete(l.stack, base)
+
+	// 环境配置与基础配置位于同一目录，扩展名可以不同
+	if p.env != "" {
+		dir := filepath.Dir(base)
+		for _, ext := range viper.SupportedExts {
+			overlay := filepath.Join(dir, p.name+"."+p.env+"."+ext)
+			if _, err := os.Stat(overlay); err != nil {
+				continue
+			}
+			if err := l.mergeFile(overlay); err != nil {
+				return nil, nil, err
+			}
+			break
+		}
+	}
+
+	if err := p.watchFiles(l.files); err != nil {
+		return nil, nil, err
+	}
+	return v.AllSettings(), l.sources, nil
+}
+
+// Watch 实现 ConfigProvider 接口
+// 监听参与合并的所有配置文件所在的目录，以兼容 Kubernetes ConfigMap 通过 ..data 软链接替换文件的方式
+func (p *FileConfigProvider) Watch(ctx context.Context, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.watcher = watcher
+	files := make([]string, 0, len(p.files))
+	for file := range p.files {
+		files = append(files, file)
+	}
+	p.mu.Unlock()
+	if err = p.watchFiles(files); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+
+	go func() {
+		// 停止监听后不再向已关闭的 watcher 添加目录
+		defer func() {
+			p.mu.Lock()
+			if p.watcher == watcher {
+				p.watcher = nil
+			}
+			p.mu.Unlock()
+			_ = watcher.Close()
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+					continue
+				}
+				p.mu.Lock()
+				matched := p.files[filepath.Clean(event.Name)] || filepath.Base(event.Name) == "..data"
+				p.mu.Unlock()
+				if matched {
+					fmt.Printf("Config file changed: %s\n", event.Name)
+					onChange()
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// watchFiles 更新监听的文件列表，includes 变化后新增的目录会加入监听
+func (p *FileConfigProvider) watchFiles(files []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files = make(map[string]bool, len(files))
+	for _, file := range files {
+		p.files[filepath.Clean(file)] = true
+		if p.watcher == nil {
+			continue
+		}
+		// 重复添加同一目录不会产生额外的监听
+		if err := p.watcher.Add(filepath.Dir(file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileLayers 一次分层加载的中间结果
+type fileLayers struct {
+	v       *viper.Viper
+	files   []string          // 参与合并的文件，按合并顺序排列
+	sources map[string]string // 配置项 -> 所在的文件
+	merged  map[string]bool   // 已合并的文件
+	stack   map[string]bool   // 当前的引入链，用于检测循环引入
+}
+
+// mergeFile 读取单个配置文件并合并到当前配置中
+// 引入链中再次出现同一文件时视为循环引入；被多个文件引入的文件只在第一次引入时合并
+func (l *fileLayers) mergeFile(file string) error {
+	file, _ = filepath.Abs(file)
+	if l.stack[file] {
+		return fmt.Errorf("config include cycle: %s", file)
+	}
+	if l.merged[file] {
+		return nil
+	}
+	l.merged[file] = true
+	l.stack[file] = true
+	defer delete(l.stack, file)
+
+	sub := viper.New()
+	sub.SetConfigFile(file)
+	if err := sub.ReadInConfig(); err != nil {
+		return fmt.Errorf("read config %s: %w", file, err)
+	}
+	settings := sub.AllSettings()
+	if err := l.v.MergeConfigMap(settings); err != nil {
+		return fmt.Errorf("merge config %s: %w", file, err)
+	}
+	l.record(file, settings)
+	return l.mergeIncludes(file, sub.GetStringSlice(configIncludeKey))
+}
+
+// mergeIncludes 合并 includes 引入的文件
+func (l *fileLayers) mergeIncludes(from string, includes []string) error {
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(from), include)
+		}
+		if err := l.mergeFile(include); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record 记录文件及其包含的配置项来源
+func (l *fileLayers) record(file string, settings map[string]any) {
+	l.files = append(l.files, file)
+	recordConfigSources(l.sources, file, settings)
+}
@@ -0,0 +1,97 @@
+package gohera
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileConfigProviderOverlay(t *testing.T) {
+	dir := writeTestConfig(t, map[string]string{
+		"app.toml":      "[http]\nport = 8080\nservice = \"demo\"\n",
+		"app.prod.yaml": "http:\n  port: 9090\n",
+		"app.test.toml": "[http]\nport = 7070\n",
+	})
+	settings, sources, err := NewFileConfigProvider("app", []string{dir}, "prod").load()
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	http := settings["http"].(map[string]any)
+	if http["port"] != 9090 || http["service"] != "demo" {
+		t.Fatalf("http = %v, want port from overlay and service from base", http)
+	}
+	if got := sources["http.port"]; got != filepath.Join(dir, "app.prod.yaml") {
+		t.Errorf("source of http.port = %s", got)
+	}
+	if got := sources["http.service"]; got != filepath.Join(dir, "app.toml") {
+		t.Errorf("source of http.service = %s", got)
+	}
+}
+
+func TestFileConfigProviderIncludes(t *testing.T) {
+	dir := writeTestConfig(t, map[string]string{
+		"app.toml":          "includes = [\"conf.d/mysql.toml\"]\n[http]\nport = 8080\n",
+		"conf.d/mysql.toml": "includes = [\"dsn.toml\"]\n[mysql.main]\nhost = \"db\"\nuser = \"app\"\n",
+		"conf.d/dsn.toml":   "[mysql.main]\nuser = \"root\"\n",
+	})
+	settings, sources, err := NewFileConfigProvider("app", []string{dir}, "").load()
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	main := settings["mysql"].(map[string]any)["main"].(map[string]any)
+	if main["host"] != "db" || main["user"] != "root" {
+		t.Fatalf("mysql.main = %v", main)
+	}
+	if got := sources["mysql.main.user"]; got != filepath.Join(dir, "conf.d", "dsn.toml") {
+		t.Errorf("source of mysql.main.user = %s", got)
+	}
+}
+
+func TestFileConfigProviderDiamondInclude(t *testing.T) {
+	dir := writeTestConfig(t, map[string]string{
+		"app.toml":           "includes = [\"shared/common.toml\"]\n",
+		"app.prod.toml":      "includes = [\"shared/common.toml\"]\n[log]\nlevel = \"warn\"\n",
+		"shared/common.toml": "[log]\nlevel = \"info\"\npath = \"/var/log\"\n",
+	})
+	settings, _, err := NewFileConfigProvider("app", []string{dir}, "prod").load()
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	log := settings["log"].(map[string]any)
+	// 第二次引入时不再合并，环境配置中的值不会被覆盖
+	if log["level"] != "warn" || log["path"] != "/var/log" {
+		t.Fatalf("log = %v", log)
+	}
+}
+
+func TestFileConfigProviderIncludeCycle(t *testing.T) {
+	dir := writeTestConfig(t, map[string]string{
+		"app.toml": "includes = [\"a.toml\"]\n",
+		"a.toml":   "includes = [\"b.toml\"]\n",
+		"b.toml":   "includes = [\"a.toml\"]\n",
+	})
+	_, _, err := NewFileConfigProvider("app", []string{dir}, "").load()
+	if err == nil || !strings.Contains(err.Error(), "config include cycle: "+filepath.Join(dir, "a.toml")) {
+		t.Fatalf("load() error = %v, want include cycle", err)
+	}
+}
+
+func TestFileConfigProviderMissingInclude(t *testing.T) {
+	dir := writeTestConfig(t, map[string]string{
+		"app.toml": "includes = [\"missing.toml\"]\n",
+	})
+	if _, _, err := NewFileConfigProvider("app", []string{dir}, "").load(); err == nil {
+		t.Fatal("load() should fail when an include is missing")
+	}
+}
+
+func TestConfigSourceEnv(t *testing.T) {
+	t.Setenv("APP_HTTP_PORT", "7000")
+	loadTestConfig(t, "", map[string]string{"app.toml": "[http]\nport = 8080\n"})
+	if got := GetInt("http.port"); got != 7000 {
+		t.Fatalf("http.port = %d, want 7000 from env", got)
+	}
+	if got := ConfigSource("http.port"); got != "env:APP_HTTP_PORT" {
+		t.Fatalf("ConfigSource() = %s", got)
+	}
+}
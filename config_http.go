@@ -0,0 +1,235 @@
+package gohera
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultConfigPollInterval 远程配置默认的轮询间隔
+const DefaultConfigPollInterval = 30 * time.Second
+
+// HTTPConfigProvider 通过 HTTP 轮询获取配置的配置源
+// 请求携带 If-None-Match，服务端返回 304 时沿用上次的配置；设置缓存文件后，每次获取成功都会保存快照，
+// 配置中心不可用时使用最近一次成功获取的配置启动
+type HTTPConfigProvider struct {
+	url       string
+	format    string
+	interval  time.Duration
+	cacheFile string
+	header    http.Header
+	client    *http.Client
+
+	mu       sync.Mutex
+	etag     string
+	body     []byte
+	settings map[string]any
+}
+
+// httpConfigSnapshot 缓存到磁盘的配置快照
+type httpConfigSnapshot struct {
+	ETag   string `json:"etag"`
+	Format string `json:"format"`
+	Body   []byte `json:"body"`
+}
+
+// NewHTTPConfigProvider 创建 HTTP 配置源，默认响应格式为 json，每 30 秒轮询一次
+func NewHTTPConfigProvider(url string) *HTTPConfigProvider {
+	return &HTTPConfigProvider{
+		url:      url,
+		format:   "json",
+		interval: DefaultConfigPollInterval,
+		header:   make(http.Header),
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// SetFormat 设置响应的配置格式 (json/toml/yaml 等)
+func (p *HTTPConfigProvider) SetFormat(format string) *HTTPConfigProvider {
+	p.format = format
+	return p
+}
+
+// SetInterval 设置轮询间隔
+func (p *HTTPConfigProvider) SetInterval(interval time.Duration) *HTTPConfigProvider {
+	if interval > 0 {
+		p.interval = interval
+	}
+	return p
+}
+
+// SetCacheFile 设置配置快照的缓存文件
+func (p *HTTPConfigProvider) SetCacheFile(file string) *HTTPConfigProvider {
+	p.cacheFile = file
+	return p
+}
+
+// SetHeader 设置请求头，如鉴权信息
+func (p *HTTPConfigProvider) SetHeader(k, v string) *HTTPConfigProvider {
+	p.header.Set(k, v)
+	return p
+}
+
+// SetClient 设置自定义的 http.Client
+func (p *HTTPConfigProvider) SetClient(client *http.Client) *HTTPConfigProvider {
+	p.client = client
+	return p
+}
+
+// Name 实现 ConfigProvider 接口
+func (p *HTTPConfigProvider) Name() string {
+	return "http:" + p.url
+}
+
+// Load 实现 ConfigProvider 接口
+// 请求失败时依次使用内存中和缓存文件中最近一次成功获取的配置
+func (p *HTTPConfigProvider) Load(ctx context.Context) (map[string]any, error) {
+	_, err := p.fetch(ctx)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		return p.settings, nil
+	}
+	if p.settings != nil {
+		fmt.Printf("Config fetch fail, use last known good: %s\n", err)
+		return p.settings, nil
+	}
+	if cacheErr := p.loadSnapshot(); cacheErr != nil {
+		return nil, err
+	}
+	fmt.Printf("Config fetch fail, use cache file %s: %s\n", p.cacheFile, err)
+	return p.settings, nil
+}
+
+// Watch 实现 ConfigProvider 接口，按轮询间隔请求配置，内容变化时调用 onChange
+func (p *HTTPConfigProvider) Watch(ctx context.Context, onChange func()) error {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				changed, err := p.fetch(ctx)
+				if err != nil {
+					fmt.Printf("Config fetch fail: %s\n", err)
+					continue
+				}
+				if changed {
+					onChange()
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// fetch 请求配置，返回配置内容是否发生变化
+func (p *HTTPConfigProvider) fetch(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header = p.header.Clone()
+	p.mu.Lock()
+	if p.etag != "" && p.settings != nil {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+	p.mu.Unlock()
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	settings, err := parseConfigBody(p.format, body)
+	if err != nil {
+		return false, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changed := !bytes.Equal(p.body, body)
+	p.etag = resp.Header.Get("ETag")
+	p.body = body
+	p.settings = settings
+	if changed {
+		if err = p.saveSnapshot(); err != nil {
+			fmt.Printf("Config cache save fail: %s\n", err)
+		}
+	}
+	return changed, nil
+}
+
+// saveSnapshot 保存配置快照，先写临时文件再重命名，避免写入中断导致快照损坏
+func (p *HTTPConfigProvider) saveSnapshot() error {
+	if p.cacheFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(&httpConfigSnapshot{ETag: p.etag, Format: p.format, Body: p.body})
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(p.cacheFile), 0o755); err != nil {
+		return err
+	}
+	tmp := p.cacheFile + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.cacheFile)
+}
+
+// loadSnapshot 从缓存文件中恢复配置
+func (p *HTTPConfigProvider) loadSnapshot() error {
+	if p.cacheFile == "" {
+		return os.ErrNotExist
+	}
+	data, err := os.ReadFile(p.cacheFile)
+	if err != nil {
+		return err
+	}
+	snapshot := new(httpConfigSnapshot)
+	if err = json.Unmarshal(data, snapshot); err != nil {
+		return err
+	}
+	settings, err := parseConfigBody(snapshot.Format, snapshot.Body)
+	if err != nil {
+		return err
+	}
+	p.etag = snapshot.ETag
+	p.body = snapshot.Body
+	p.settings = settings
+	return nil
+}
+
+// parseConfigBody 按指定格式解析配置内容
+func parseConfigBody(format string, body []byte) (map[string]any, error) {
+	v := viper.New()
+	v.SetConfigType(format)
+	if err := v.ReadConfig(bytes.NewReader(body)); err != nil {
+		return nil, err
+	}
+	return v.AllSettings(), nil
+}
@@ -0,0 +1,184 @@
+package gohera
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testConfigServer 返回可修改内容的配置中心，携带的 If-None-Match 与当前 ETag 相同时返回 304
+type testConfigServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	body     string
+	etag     string
+	status   int
+	requests int
+	header   http.Header
+}
+
+func newTestConfigServer(t *testing.T, body, etag string) *testConfigServer {
+	s := &testConfigServer{body: body, etag: etag, status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests++
+		s.header = r.Header.Clone()
+		if s.status != http.StatusOK {
+			w.WriteHeader(s.status)
+			return
+		}
+		if s.etag != "" && r.Header.Get("If-None-Match") == s.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", s.etag)
+		_, _ = w.Write([]byte(s.body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testConfigServer) set(body, etag string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.etag, s.status = body, etag, status
+}
+
+// resetConfigProviders 清空已添加的配置源，测试结束后恢复
+func resetConfigProviders(t *testing.T) {
+	configProviderMu.Lock()
+	saved := configProviders
+	configProviders = nil
+	configProviderSeq++
+	configProviderMu.Unlock()
+	t.Cleanup(func() {
+		configProviderMu.Lock()
+		configProviders = saved
+		configProviderSeq++
+		configProviderMu.Unlock()
+	})
+}
+
+func TestHTTPConfigProviderLoad(t *testing.T) {
+	s := newTestConfigServer(t, `{"limit": {"rate": 10}}`, `"v1"`)
+	p := NewHTTPConfigProvider(s.URL).SetHeader("Authorization", "Bearer abc")
+
+	settings, err := p.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if rate := settings["limit"].(map[string]any)["rate"]; rate != float64(10) {
+		t.Fatalf("limit.rate = %v, want 10", rate)
+	}
+	if got := s.header.Get("Authorization"); got != "Bearer abc" {
+		t.Fatalf("Authorization = %q", got)
+	}
+
+	// 内容未变化时返回 304，沿用上次的配置
+	changed, err := p.fetch(context.Background())
+	if err != nil || changed {
+		t.Fatalf("fetch() = %v, %v, want unchanged", changed, err)
+	}
+	if got := s.header.Get("If-None-Match"); got != `"v1"` {
+		t.Fatalf("If-None-Match = %q", got)
+	}
+
+	s.set(`{"limit": {"rate": 20}}`, `"v2"`, http.StatusOK)
+	if changed, err = p.fetch(context.Background()); err != nil || !changed {
+		t.Fatalf("fetch() = %v, %v, want changed", changed, err)
+	}
+}
+
+func TestHTTPConfigProviderFormat(t *testing.T) {
+	s := newTestConfigServer(t, "[limit]\nrate = 10\n", "")
+	settings, err := NewHTTPConfigProvider(s.URL).SetFormat("toml").Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if rate := settings["limit"].(map[string]any)["rate"]; rate != int64(10) {
+		t.Fatalf("limit.rate = %v, want 10", rate)
+	}
+}
+
+func TestHTTPConfigProviderFailure(t *testing.T) {
+	s := newTestConfigServer(t, `{"limit": {"rate": 10}}`, `"v1"`)
+	cacheFile := filepath.Join(t.TempDir(), "config.snapshot")
+
+	// 配置中心不可用且没有缓存时返回错误
+	s.set("", "", http.StatusInternalServerError)
+	if _, err := NewHTTPConfigProvider(s.URL).Load(context.Background()); err == nil {
+		t.Fatal("Load() should fail without cache")
+	}
+
+	// 获取成功后保存快照，失败时使用最近一次成功获取的配置
+	s.set(`{"limit": {"rate": 10}}`, `"v1"`, http.StatusOK)
+	p := NewHTTPConfigProvider(s.URL).SetCacheFile(cacheFile)
+	if _, err := p.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	s.set("", "", http.StatusServiceUnavailable)
+	settings, err := p.Load(context.Background())
+	if err != nil || settings["limit"].(map[string]any)["rate"] != float64(10) {
+		t.Fatalf("Load() = %v, %v, want last known good", settings, err)
+	}
+
+	// 重启后使用缓存文件中的快照
+	settings, err = NewHTTPConfigProvider(s.URL).SetCacheFile(cacheFile).Load(context.Background())
+	if err != nil || settings["limit"].(map[string]any)["rate"] != float64(10) {
+		t.Fatalf("Load() = %v, %v, want cached snapshot", settings, err)
+	}
+
+	// 响应内容无法解析时不覆盖已有的配置
+	s.set(`{"limit":`, `"v3"`, http.StatusOK)
+	if _, err = p.fetch(context.Background()); err == nil {
+		t.Fatal("fetch() should fail on invalid body")
+	}
+	if settings, _ = p.Load(context.Background()); settings["limit"].(map[string]any)["rate"] != float64(10) {
+		t.Fatalf("settings = %v, want last known good", settings)
+	}
+}
+
+func TestHTTPConfigProviderReload(t *testing.T) {
+	resetConfigProviders(t)
+	resetConfigListeners(t)
+	s := newTestConfigServer(t, `{"limit": {"rate": 20}}`, `"v1"`)
+	AddConfigProvider(NewHTTPConfigProvider(s.URL).SetInterval(10*time.Millisecond), 10)
+	// 测试结束后停止轮询
+	savedCtx, savedCancel := configWatchCtx, configWatchCancel
+	configWatchCtx, configWatchCancel = context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		configWatchCancel()
+		configWatchCtx, configWatchCancel = savedCtx, savedCancel
+	})
+	loadTestConfig(t, "", map[string]string{"app.toml": "[limit]\nrate = 10\ncapacity = 5\n"})
+
+	// 配置源按 priority 覆盖本地文件
+	if GetInt("limit.rate") != 20 || GetInt("limit.capacity") != 5 {
+		t.Fatalf("limit = %d/%d, want 20/5", GetInt("limit.rate"), GetInt("limit.capacity"))
+	}
+	if got := ConfigSource("limit.rate"); got != "http:"+s.URL {
+		t.Fatalf("ConfigSource() = %q", got)
+	}
+
+	changed := make(chan any, 1)
+	OnChange("limit.rate", func(_, new any) {
+		select {
+		case changed <- new:
+		default:
+		}
+	})
+	s.set(`{"limit": {"rate": 30}}`, `"v2"`, http.StatusOK)
+	select {
+	case v := <-changed:
+		if v != float64(30) {
+			t.Fatalf("new value = %v, want 30", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("config change was not detected by polling")
+	}
+}
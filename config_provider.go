@@ -0,0 +1,170 @@
+package gohera
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/metlive/gohera/secret"
+	"github.com/spf13/viper"
+)
+
+// configEnvPrefix 环境变量覆盖配置时使用的前缀
+const configEnvPrefix = "APP"
+
+var configEnvReplacer = strings.NewReplacer(".", "_")
+
+// ConfigProvider 配置源
+type ConfigProvider interface {
+	// Name 配置源名称，用于标注配置项的来源
+	Name() string
+	// Load 加载配置，返回嵌套的配置项
+	Load(ctx context.Context) (map[string]any, error)
+	// Watch 开始监听配置变化，配置变化时调用 onChange，ctx 取消后停止监听
+	// Watch 不应阻塞，返回错误表示无法开始监听
+	Watch(ctx context.Context, onChange func()) error
+}
+
+type configProviderEntry struct {
+	provider ConfigProvider
+	priority int
+	watching bool
+}
+
+var (
+	configProviders   []*configProviderEntry
+	configProviderMu  sync.Mutex
+	configProviderSeq int // 配置源变更的次数，用于判断是否需要重新加载
+)
+
+// AddConfigProvider 添加配置源，需要在 New/InitApp 之前调用
+// 所有配置源按 priority 从小到大合并，priority 大的覆盖小的；本地文件配置源的 priority 为 0，APP_* 环境变量始终最优先
+func AddConfigProvider(p ConfigProvider, priority int) {
+	configProviderMu.Lock()
+	defer configProviderMu.Unlock()
+	configProviders = append(configProviders, &configProviderEntry{provider: p, priority: priority})
+	configProviderSeq++
+}
+
+// configBuild 一次加载的结果
+type configBuild struct {
+	v       *viper.Viper
+	sources map[string]string // 配置项 -> 生效值的来源
+	secrets map[string]bool   // 解密自 ENC(...) 的配置项
+}
+
+// buildConfig 按优先级加载并合并所有配置源，最后应用 APP_* 环境变量并解密 ENC(...) 配置值
+func buildConfig(ctx context.Context, entries []*configProviderEntry) (*configBuild, error) {
+	sorted := make([]*configProviderEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].priority < sorted[j].priority
+	})
+
+	b := &configBuild{
+		v:       viper.New(),
+		sources: make(map[string]string),
+		secrets: make(map[string]bool),
+	}
+	for _, e := range sorted {
+		var settings map[string]any
+		var sources map[string]string
+		var err error
+		if fp, ok := e.provider.(*FileConfigProvider); ok {
+			settings, sources, err = fp.load()
+		} else {
+			settings, err = e.provider.Load(ctx)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("load config from %s: %w", e.provider.Name(), err)
+		}
+		if err = b.v.MergeConfigMap(settings); err != nil {
+			return nil, fmt.Errorf("merge config from %s: %w", e.provider.Name(), err)
+		}
+		if sources != nil {
+			for k, s := range sources {
+				b.sources[k] = s
+			}
+		} else {
+			recordConfigSources(b.sources, e.provider.Name(), settings)
+		}
+	}
+
+	b.v.SetEnvPrefix(configEnvPrefix)
+	b.v.AutomaticEnv()
+	b.v.SetEnvKeyReplacer(configEnvReplacer)
+	for key := range b.sources {
+		envKey := configEnvPrefix + "_" + strings.ToUpper(configEnvReplacer.Replace(key))
+		if _, ok := os.LookupEnv(envKey); ok {
+			b.sources[key] = "env:" + envKey
+		}
+	}
+	if err := b.decryptSecrets(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// recordConfigSources 记录配置中所有叶子配置项的来源
+func recordConfigSources(sources map[string]string, source string, settings map[string]any) {
+	flat := make(map[string]any)
+	flattenSettings("", settings, flat)
+	for key, value := range flat {
+		if _, ok := value.(map[string]any); ok {
+			continue
+		}
+		sources[strings.ToLower(key)] = source
+	}
+}
+
+// decryptSecrets 解密 ENC(...) 格式的配置值，解密后的值对 GetString、UnmarshalKey 和配置缓存均生效
+// 包含密文的顶级配置项会以解密后的完整子树覆盖，避免 viper 中单个子项覆盖导致同级配置丢失
+// 解密过的配置项记录在 secrets 中，输出配置时始终脱敏
+func (b *configBuild) decryptSecrets() error {
+	settings := b.v.AllSettings()
+	var key []byte
+	touched := make(map[string]bool)
+	var walk func(prefix string, m map[string]any) error
+	walk = func(prefix string, m map[string]any) error {
+		for k, val := range m {
+			fullKey := k
+			if prefix != "" {
+				fullKey = prefix + "." + k
+			}
+			if sub, ok := val.(map[string]any); ok {
+				if err := walk(fullKey, sub); err != nil {
+					return err
+				}
+				continue
+			}
+			s, ok := val.(string)
+			if !ok || !secret.IsEncrypted(s) {
+				continue
+			}
+			if key == nil {
+				var err error
+				if key, err = secret.LoadKey(); err != nil {
+					return fmt.Errorf("decrypt config %s: %w", fullKey, err)
+				}
+			}
+			plain, err := secret.Decrypt(key, s)
+			if err != nil {
+				return fmt.Errorf("decrypt config %s: %w", fullKey, err)
+			}
+			m[k] = plain
+			b.secrets[fullKey] = true
+			touched[strings.SplitN(fullKey, ".", 2)[0]] = true
+		}
+		return nil
+	}
+	if err := walk("", settings); err != nil {
+		return err
+	}
+	for top := range touched {
+		b.v.Set(top, settings[top])
+	}
+	return nil
+}
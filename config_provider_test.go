@@ -0,0 +1,50 @@
+package gohera
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/metlive/gohera/secret"
+)
+
+func TestDecryptSecrets(t *testing.T) {
+	t.Setenv(secret.KeyEnv, "test-key")
+	enc, err := secret.Encrypt(secret.ParseKey("test-key"), "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadTestConfig(t, "", map[string]string{"app.toml": `
+[mysql.main]
+host = "db"
+user = "app"
+database = "orders"
+password = "` + enc + `"
+`})
+
+	var main struct {
+		Host     string `mapstructure:"host"`
+		User     string `mapstructure:"user"`
+		Database string `mapstructure:"database"`
+		Password string `mapstructure:"password"`
+	}
+	if err := UnmarshalKey("mysql.main", &main); err != nil {
+		t.Fatal(err)
+	}
+	// 解密后的值不会覆盖同级配置
+	if main.Host != "db" || main.User != "app" || main.Database != "orders" || main.Password != "s3cret" {
+		t.Fatalf("mysql.main = %+v", main)
+	}
+	if got := GetString("mysql.main.password"); got != "s3cret" {
+		t.Fatalf("GetString() = %q", got)
+	}
+}
+
+func TestDecryptSecretsWrongKey(t *testing.T) {
+	enc, _ := secret.Encrypt(secret.ParseKey("test-key"), "s3cret")
+	t.Setenv(secret.KeyEnv, "other-key")
+	dir := writeTestConfig(t, map[string]string{"app.toml": "[redis]\npassword = \"" + enc + "\"\n"})
+	err := loadAppConfig("app", []string{dir}, "")
+	if err == nil || !strings.Contains(err.Error(), "decrypt config redis.password") {
+		t.Fatalf("loadAppConfig() error = %v, want decrypt error", err)
+	}
+}
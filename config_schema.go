@@ -0,0 +1,129 @@
+package gohera
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metlive/gohera/validator"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+type configSchema struct {
+	key string
+	typ reflect.Type
+}
+
+var (
+	configSchemas  []configSchema
+	configSchemaMu sync.RWMutex
+)
+
+// httpConfig [http] 配置
+type httpConfig struct {
+	Host            string        `mapstructure:"host"`
+	Port            int           `mapstructure:"port" binding:"required,min=1,max=65535"`
+	Service         string        `mapstructure:"service"`
+	Pprof           int           `mapstructure:"pprof" binding:"oneof=0 1"` // 是否在业务端口上开启 pprof
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	ShutdownDelay   time.Duration `mapstructure:"shutdown_delay"`
+}
+
+// ConfigValidationError 配置验证错误，包含所有不合法及未知的配置项
+type ConfigValidationError struct {
+	Errors []string
+}
+
+// Error 实现 error 接口
+func (e *ConfigValidationError) Error() string {
+	return "config validation failed:\n  - " + strings.Join(e.Errors, "\n  - ")
+}
+
+// RegisterConfigSchema 注册配置结构体，启动及每次热更新时对 key 下的配置进行验证
+// 字段名使用 mapstructure 标签，验证规则使用 binding 标签；key 以 .* 结尾时验证其下的每个子项
+// 结构体中未定义的配置项视为未知配置项
+func RegisterConfigSchema(key string, schema any) {
+	typ := reflect.TypeOf(schema)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		panic("gohera: config schema must be a struct")
+	}
+
+	configSchemaMu.Lock()
+	defer configSchemaMu.Unlock()
+	key = strings.ToLower(key)
+	for i, s := range configSchemas {
+		if s.key == key {
+			configSchemas[i].typ = typ
+			return
+		}
+	}
+	configSchemas = append(configSchemas, configSchema{key: key, typ: typ})
+}
+
+// ValidateConfig 使用已注册的配置结构体验证当前配置
+func ValidateConfig() error {
+	return validateConfig(currentConfig())
+}
+
+// validateConfig 验证配置，返回汇总所有错误的 ConfigValidationError
+func validateConfig(v *viper.Viper) error {
+	configSchemaMu.RLock()
+	schemas := make([]configSchema, len(configSchemas))
+	copy(schemas, configSchemas)
+	configSchemaMu.RUnlock()
+
+	var errs []string
+	for _, s := range schemas {
+		if prefix, ok := strings.CutSuffix(s.key, ".*"); ok {
+			children := make([]string, 0)
+			for child := range v.GetStringMap(prefix) {
+				children = append(children, child)
+			}
+			sort.Strings(children)
+			for _, child := range children {
+				errs = append(errs, validateConfigKey(v, prefix+"."+child, s.typ)...)
+			}
+			continue
+		}
+		errs = append(errs, validateConfigKey(v, s.key, s.typ)...)
+	}
+	if len(errs) > 0 {
+		return &ConfigValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// validateConfigKey 将 key 下的配置反序列化到结构体中并验证
+func validateConfigKey(v *viper.Viper, key string, typ reflect.Type) []string {
+	var errs []string
+	ptr := reflect.New(typ)
+	md := new(mapstructure.Metadata)
+	err := v.UnmarshalKey(key, ptr.Interface(), viper.DecoderConfigOption(func(dc *mapstructure.DecoderConfig) {
+		dc.Metadata = md
+	}))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("%s: %s", key, err))
+	}
+
+	unused := md.Unused
+	sort.Strings(unused)
+	for _, name := range unused {
+		errs = append(errs, fmt.Sprintf("%s.%s: 未知的配置项", key, name))
+	}
+
+	fields, err := validator.ValidateFields(ptr.Interface(), "mapstructure")
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("%s: %s", key, err))
+	}
+	for _, f := range fields {
+		errs = append(errs, fmt.Sprintf("%s.%s: %s", key, f.Field, f.Message))
+	}
+	return errs
+}
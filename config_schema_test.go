@@ -0,0 +1,117 @@
+package gohera
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/metlive/gohera/mysql"
+)
+
+// resetConfigSchemas 清空已注册的配置结构体，测试结束后恢复
+func resetConfigSchemas(t *testing.T) {
+	configSchemaMu.Lock()
+	saved := configSchemas
+	configSchemas = nil
+	configSchemaMu.Unlock()
+	t.Cleanup(func() {
+		configSchemaMu.Lock()
+		configSchemas = saved
+		configSchemaMu.Unlock()
+	})
+}
+
+func TestValidateConfigHttp(t *testing.T) {
+	resetConfigSchemas(t)
+	RegisterConfigSchema("http", httpConfig{})
+
+	loadTestConfig(t, "", map[string]string{"app.toml": "[http]\nhost = \"0.0.0.0\"\nport = 8080\nservice = \"demo\"\npprof = 1\nshutdown_timeout = \"15s\"\n"})
+	if err := ValidateConfig(); err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+
+	loadTestConfig(t, "", map[string]string{"app.toml": "[http]\nhost = \"0.0.0.0\"\nprot = 8080\n"})
+	err := ValidateConfig()
+	var ve *ConfigValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("ValidateConfig() error = %v, want ConfigValidationError", err)
+	}
+	want := []string{"http.prot: 未知的配置项", "http.port"}
+	if len(ve.Errors) != len(want) {
+		t.Fatalf("errors = %q", ve.Errors)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(ve.Errors[i], w) {
+			t.Errorf("errors[%d] = %q, want prefix %q", i, ve.Errors[i], w)
+		}
+	}
+}
+
+func TestValidateConfigWildcard(t *testing.T) {
+	resetConfigSchemas(t)
+	RegisterConfigSchema("mysql.*", mysql.Config{})
+	loadTestConfig(t, "", map[string]string{"app.toml": `
+[mysql.main]
+host = "db"
+user = "app"
+database = "orders"
+max_open_conn = 10
+
+[mysql.log]
+host = "db"
+user = "app"
+max_open_conns = -1
+`})
+	err := ValidateConfig()
+	var ve *ConfigValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("ValidateConfig() error = %v, want ConfigValidationError", err)
+	}
+	joined := strings.Join(ve.Errors, "\n")
+	for _, w := range []string{"mysql.log.database", "mysql.log.max_open_conns", "mysql.main.max_open_conn: 未知的配置项"} {
+		if !strings.Contains(joined, w) {
+			t.Errorf("errors = %q, want to contain %q", ve.Errors, w)
+		}
+	}
+	if len(ve.Errors) != 3 {
+		t.Errorf("errors = %q, want 3", ve.Errors)
+	}
+}
+
+func TestValidateConfigReloadRejected(t *testing.T) {
+	resetConfigSchemas(t)
+	RegisterConfigSchema("http", httpConfig{})
+	dir := loadTestConfig(t, "", map[string]string{"app.toml": "[http]\nport = 8080\n"})
+	rewriteTestConfig(t, dir, "app.toml", "[http]\nport = 0\n")
+	if got := GetInt("http.port"); got != 8080 {
+		t.Fatalf("http.port = %d, want 8080 after rejected reload", got)
+	}
+	rewriteTestConfig(t, dir, "app.toml", "[http]\nport = 9090\n")
+	if got := GetInt("http.port"); got != 9090 {
+		t.Fatalf("http.port = %d, want 9090 after valid reload", got)
+	}
+}
+
+func TestRegisterConfigSchemaPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("RegisterConfigSchema() should panic for non-struct schema")
+		}
+	}()
+	RegisterConfigSchema("bad", 1)
+}
+
+func TestHttpPprof(t *testing.T) {
+	loadTestConfig(t, "", map[string]string{"app.toml": "[http]\nport = 8080\npprof = 1\n"})
+	app, err := New(bareOptions()...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	var found bool
+	for _, r := range app.Engine.Routes() {
+		found = found || strings.HasPrefix(r.Path, "/debug/pprof")
+	}
+	if !found {
+		t.Fatal("pprof is not registered with http.pprof = 1")
+	}
+}
@@ -0,0 +1,72 @@
+package gohera
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type configListener struct {
+	key string
+	fn  func(old, new any)
+}
+
+var (
+	configListeners []configListener
+	configListenMu  sync.RWMutex
+)
+
+// OnChange 订阅配置项变更
+// 配置热更新后，仅当 key 对应的值（或子树）发生变化时回调，old/new 为变更前后的值，不存在时为 nil
+// 回调在配置监听协程中同步执行，回调中的 panic 会被捕获并记录日志
+func OnChange(key string, fn func(old, new any)) {
+	if fn == nil {
+		return
+	}
+	configListenMu.Lock()
+	defer configListenMu.Unlock()
+	configListeners = append(configListeners, configListener{key: strings.ToLower(key), fn: fn})
+}
+
+// Watch 订阅配置项变更，并将变更后的子树反序列化为 T
+// 反序列化失败时不会回调，并记录错误日志
+func Watch[T any](key string, fn func(T)) {
+	if fn == nil {
+		return
+	}
+	OnChange(key, func(_, _ any) {
+		var val T
+		if err := UnmarshalKey(key, &val); err != nil {
+			Errortf(context.Background(), "config watch %s unmarshal fail: %v", key, err)
+			return
+		}
+		fn(val)
+	})
+}
+
+// notifyConfigChange 比较新旧配置快照，通知发生变化的订阅者
+func notifyConfigChange(old, new map[string]any) {
+	configListenMu.RLock()
+	listeners := make([]configListener, len(configListeners))
+	copy(listeners, configListeners)
+	configListenMu.RUnlock()
+
+	for _, l := range listeners {
+		oldVal, newVal := old[l.key], new[l.key]
+		if reflect.DeepEqual(oldVal, newVal) {
+			continue
+		}
+		runConfigListener(l, oldVal, newVal)
+	}
+}
+
+// runConfigListener 执行单个订阅回调，隔离回调中的 panic
+func runConfigListener(l configListener, old, new any) {
+	defer func() {
+		if r := recover(); r != nil {
+			Errortf(context.Background(), "config listener %s panic: %v", l.key, r)
+		}
+	}()
+	l.fn(old, new)
+}
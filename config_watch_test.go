@@ -0,0 +1,153 @@
+package gohera
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// resetConfigListeners 清空配置订阅，测试结束后恢复
+func resetConfigListeners(t *testing.T) {
+	configListenMu.Lock()
+	saved := configListeners
+	configListeners = nil
+	configListenMu.Unlock()
+	t.Cleanup(func() {
+		configListenMu.Lock()
+		configListeners = saved
+		configListenMu.Unlock()
+	})
+}
+
+// rewriteTestConfig 停止文件监听并修改配置文件，然后立即重新加载
+// 先写临时文件再重命名，已收到事件的文件监听不会读取到写入中的文件
+func rewriteTestConfig(t *testing.T, dir, name, content string) {
+	t.Helper()
+	configReloadMu.Lock()
+	if configFileCancel != nil {
+		configFileCancel()
+		configFileCancel = nil
+	}
+	configReloadMu.Unlock()
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file+".tmp", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(file+".tmp", file); err != nil {
+		t.Fatal(err)
+	}
+	reloadAppConfig()
+}
+
+type testLimitConfig struct {
+	Rate     int `mapstructure:"rate"`
+	Capacity int `mapstructure:"capacity"`
+}
+
+func TestOnChange(t *testing.T) {
+	resetConfigListeners(t)
+	dir := loadTestConfig(t, "", map[string]string{"app.toml": "[limit]\nrate = 10\ncapacity = 20\n[other]\nv = 1\n"})
+
+	var mu sync.Mutex
+	var changes [][2]any
+	OnChange("limit.rate", func(old, new any) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, [2]any{old, new})
+	})
+	var typed []testLimitConfig
+	Watch("limit", func(c testLimitConfig) {
+		typed = append(typed, c)
+	})
+	OnChange("limit", func(old, new any) {
+		panic("listener panic")
+	})
+
+	rewriteTestConfig(t, dir, "app.toml", "[limit]\nrate = 10\ncapacity = 20\n[other]\nv = 2\n")
+	if len(changes) != 0 || len(typed) != 0 {
+		t.Fatalf("listeners called on unrelated change: %v %v", changes, typed)
+	}
+
+	rewriteTestConfig(t, dir, "app.toml", "[limit]\nrate = 30\ncapacity = 20\n")
+	if len(changes) != 1 || changes[0][0] != int64(10) || changes[0][1] != int64(30) {
+		t.Fatalf("changes = %v, want [10 30]", changes)
+	}
+	if len(typed) != 1 || typed[0] != (testLimitConfig{Rate: 30, Capacity: 20}) {
+		t.Fatalf("typed = %+v", typed)
+	}
+
+	rewriteTestConfig(t, dir, "app.toml", "[other]\nv = 2\n")
+	if len(changes) != 2 || changes[1][0] != int64(30) || changes[1][1] != nil {
+		t.Fatalf("changes = %v, want removal as nil", changes)
+	}
+}
+
+func TestConfigReloadKeepsConfigOnError(t *testing.T) {
+	dir := loadTestConfig(t, "", map[string]string{"app.toml": "[limit]\nrate = 10\n"})
+	rewriteTestConfig(t, dir, "app.toml", "[limit\nrate = 20\n")
+	if got := GetInt("limit.rate"); got != 10 {
+		t.Fatalf("limit.rate = %d, want 10 after invalid reload", got)
+	}
+}
+
+func TestConfigReloadConcurrentRead(t *testing.T) {
+	dir := loadTestConfig(t, "", map[string]string{"app.toml": "[limit]\nrate = 10\n"})
+	stop, started := make(chan struct{}), make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	// 热更新的同时读取配置，go test -race 时检查数据竞争
+	go func() {
+		defer wg.Done()
+		close(started)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			var cfg testLimitConfig
+			_ = UnmarshalKey("limit", &cfg)
+			_ = IsSet("limit.missing")
+			_ = GetString("limit.missing")
+		}
+	}()
+	<-started
+	for i := 0; i < 5; i++ {
+		rewriteTestConfig(t, dir, "app.toml", "[limit]\nrate = "+strconv.Itoa(20+i)+"\n")
+	}
+	close(stop)
+	wg.Wait()
+	if got := GetInt("limit.rate"); got != 24 {
+		t.Fatalf("limit.rate = %d, want 24", got)
+	}
+}
+
+func TestConfigFileWatch(t *testing.T) {
+	resetConfigListeners(t)
+	dir := loadTestConfig(t, "", map[string]string{"app.toml": "[limit]\nrate = 10\n"})
+	changed := make(chan any, 16)
+	OnChange("limit.rate", func(_, new any) {
+		select {
+		case changed <- new:
+		default:
+		}
+	})
+	if err := os.WriteFile(filepath.Join(dir, "app.toml"), []byte("[limit]\nrate = 50\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 写入过程中可能先读取到截断的文件，以最终的值为准
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case v := <-changed:
+			if v == int64(50) {
+				return
+			}
+		case <-timeout:
+			t.Fatal("config change was not detected by the file watcher")
+		}
+	}
+}
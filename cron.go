@@ -41,10 +41,13 @@ type Manager struct {
 }
 
 // NewJobManager 创建定时任务管理器
+// 服务退出时会自动停止调度，并等待正在执行的任务结束
 func NewJobManager() *Manager {
-	return &Manager{
+	m := &Manager{
 		run: cron.New(cron.WithSeconds()),
 	}
+	OnShutdown("cron", m.shutdown)
+	return m
 }
 
 // Command 设置当前任务的名称和处理函数
@@ -70,6 +73,16 @@ func (m *Manager) Stop() {
 	m.run.Stop()
 }
 
+// shutdown 停止调度并等待正在执行的任务结束，超时则直接返回
+func (m *Manager) shutdown(ctx context.Context) error {
+	select {
+	case <-m.run.Stop().Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Start 启动定时任务
 func (m *Manager) Start() {
 	m.run.Start()
@@ -8,19 +8,25 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
-	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// DefaultShutdownTimeout 默认的平滑退出等待时间
+const DefaultShutdownTimeout = 10 * time.Second
+
+// adminShutdownTimeout 运维服务的关闭等待时间，运维接口均为短请求
+const adminShutdownTimeout = 3 * time.Second
+
 var (
 	httpHost string
 	httpPort int
 )
 
 // StartupService 启动 HTTP 服务
-// 根据配置启动 Gin 引擎，并处理平滑退出信号
+// 根据配置启动 Gin 引擎（开启 admin.port 时同时启动运维服务），收到退出信号后在 http.shutdown_timeout 内等待请求处理完毕，并执行退出钩子
 func StartupService(engine *gin.Engine) {
 	httpHost = GetString("http.host")
 	httpPort = GetInt("http.port")
@@ -28,27 +34,72 @@ func StartupService(engine *gin.Engine) {
 		panic(errors.New("http host or port is not valid"))
 	}
 	addr := httpHost + ":" + strconv.Itoa(httpPort)
-	ac := make(chan error)
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: engine,
+	}
+	ac := make(chan error, 2)
 	go func() {
 		fmt.Printf("服务启动，运行模式：%v，版本号：%s，进程号：%d , ip：%s", GetEnv(), GetAppVersion(), os.Getpid(), addr)
 		fmt.Println("")
-		err := engine.Run(addr)
-		if err != nil && errors.Is(err, http.ErrServerClosed) {
-			fmt.Printf("监听HTTP服务: %v", err.Error())
+		err := srv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			ac <- err
 		}
 	}()
-	var state int32 = 1
+	adminSrv := newAdminServer()
+	if adminSrv != nil {
+		go func() {
+			fmt.Printf("运维服务启动，ip：%s", adminSrv.Addr)
+			fmt.Println("")
+			err := adminSrv.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				ac <- err
+			}
+		}()
+	}
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGKILL, syscall.SIGQUIT, syscall.SIGTERM)
+	signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
 	select {
 	case err := <-ac:
-		if err != nil && atomic.LoadInt32(&state) == 1 {
-			Error(context.Background(), "监听HTTP服务发生错误: %v", err.Error())
-			panic(fmt.Sprintf("监听HTTP服务发生错误: %v", err.Error()))
-		}
+		Error(context.Background(), "监听HTTP服务发生错误: %v", err.Error())
+		_ = Shutdown(context.Background())
+		panic(fmt.Sprintf("监听HTTP服务发生错误: %v", err.Error()))
 	case sig := <-quit:
-		atomic.StoreInt32(&state, 0)
 		fmt.Printf("获取到退出信号: %v  pid %d", sig.String(), os.Getpid())
+		fmt.Println("")
+	}
+
+	// 就绪检查立即返回失败，等待负载均衡摘除流量后再关闭监听
+	shuttingDown.Store(true)
+	if delay := GetDuration("http.shutdown_delay"); delay > 0 {
+		time.Sleep(delay)
+	}
+
+	timeout := GetDuration("http.shutdown_timeout")
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), timeout)
+	defer drainCancel()
+	if err := srv.Shutdown(drainCtx); err != nil {
+		fmt.Printf("HTTP服务关闭超时: %v", err.Error())
+		fmt.Println("")
+	}
+	// 运维服务在业务请求排空后再关闭，保证排空期间仍可进行健康检查
+	// 使用独立的超时时间，业务请求排空耗尽时间后运维服务仍可平滑关闭
+	if adminSrv != nil {
+		adminCtx, adminCancel := context.WithTimeout(context.Background(), adminShutdownTimeout)
+		_ = adminSrv.Shutdown(adminCtx)
+		adminCancel()
+	}
+	// 退出钩子使用独立的超时时间，避免请求排空耗尽时间后资源无法释放
+	hookCtx, hookCancel := context.WithTimeout(context.Background(), timeout)
+	defer hookCancel()
+	if err := Shutdown(hookCtx); err != nil {
+		fmt.Printf("执行退出钩子发生错误: %v", err.Error())
+		fmt.Println("")
 	}
+	fmt.Printf("服务已退出 pid %d", os.Getpid())
+	fmt.Println("")
 }
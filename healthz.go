@@ -1,16 +1,191 @@
 package gohera
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+
+	// DefaultHealthTimeout 默认的单项检查超时时间
+	DefaultHealthTimeout = 2 * time.Second
+	// DefaultHealthCacheTTL 默认的检查结果缓存时间
+	DefaultHealthCacheTTL = time.Second
+)
+
 type healthZ struct {
 	Status int    `json:"status"`
 	Env    string `json:"env"`
 }
 
+// HealthCheckFunc 依赖检查函数，返回 nil 表示依赖正常
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthOption 健康检查选项
+type HealthOption func(*healthChecker)
+
+// WithHealthTimeout 设置单项检查的超时时间，默认为 health.timeout 或 2s
+func WithHealthTimeout(timeout time.Duration) HealthOption {
+	return func(h *healthChecker) {
+		h.timeout = timeout
+	}
+}
+
+// HealthResult 单项检查结果
+type HealthResult struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthReport 健康检查报告
+type HealthReport struct {
+	Status string                  `json:"status"`
+	Env    string                  `json:"env"`
+	Checks map[string]HealthResult `json:"checks,omitempty"`
+}
+
+type healthChecker struct {
+	name    string
+	fn      HealthCheckFunc
+	timeout time.Duration
+
+	mu      sync.Mutex
+	result  HealthResult
+	checkAt time.Time
+}
+
+var (
+	healthCheckers []*healthChecker
+	healthMu       sync.RWMutex
+	shuttingDown   atomic.Bool
+)
+
+// RegisterHealthChecker 注册依赖检查，同名检查会被替换
+// 就绪检查 (/healthz/ready) 会执行所有已注册的检查，任一失败即返回 503
+func RegisterHealthChecker(name string, fn HealthCheckFunc, opts ...HealthOption) {
+	h := &healthChecker{name: name, fn: fn}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	for i, c := range healthCheckers {
+		if c.name == name {
+			healthCheckers[i] = h
+			return
+		}
+	}
+	healthCheckers = append(healthCheckers, h)
+}
+
+// check 执行检查，在缓存有效期内直接返回上次的结果
+// 请求被取消或超时导致的失败不代表依赖异常，不缓存该结果
+func (h *healthChecker) check(parent context.Context, ttl time.Duration) HealthResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.checkAt.IsZero() && time.Since(h.checkAt) < ttl {
+		return h.result
+	}
+
+	timeout := h.timeout
+	if timeout <= 0 {
+		timeout = GetDuration("health.timeout")
+	}
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := runHealthCheck(ctx, h.fn)
+	result := HealthResult{
+		Status:    HealthStatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = HealthStatusDown
+		result.Error = err.Error()
+		if parent.Err() != nil {
+			return result
+		}
+	}
+	h.result = result
+	h.checkAt = time.Now()
+	return h.result
+}
+
+// runHealthCheck 在独立的协程中执行检查，超时后不再等待检查函数返回
+func runHealthCheck(ctx context.Context, fn HealthCheckFunc) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CheckHealth 并发执行所有已注册的依赖检查并汇总结果
+func CheckHealth(ctx context.Context) *HealthReport {
+	healthMu.RLock()
+	checkers := make([]*healthChecker, len(healthCheckers))
+	copy(checkers, healthCheckers)
+	healthMu.RUnlock()
+
+	ttl := DefaultHealthCacheTTL
+	if IsSet("health.cache_ttl") {
+		ttl = GetDuration("health.cache_ttl")
+	}
+
+	results := make([]HealthResult, len(checkers))
+	var wg sync.WaitGroup
+	for i, c := range checkers {
+		wg.Add(1)
+		go func(i int, c *healthChecker) {
+			defer wg.Done()
+			results[i] = c.check(ctx, ttl)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := &HealthReport{
+		Status: HealthStatusUp,
+		Env:    GetEnv(),
+		Checks: make(map[string]HealthResult, len(checkers)),
+	}
+	for i, c := range checkers {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != HealthStatusUp {
+			report.Status = HealthStatusDown
+		}
+	}
+	return report
+}
+
+// IsShuttingDown 服务是否正在退出
+func IsShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
 // healthCheck 健康检查接口
 func healthCheck(c *gin.Context) {
 	h := &healthZ{
@@ -19,3 +194,35 @@ func healthCheck(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, h)
 }
+
+// livenessCheck 存活检查接口，进程能够响应即为存活
+func livenessCheck(c *gin.Context) {
+	c.JSON(http.StatusOK, &HealthReport{
+		Status: HealthStatusUp,
+		Env:    GetEnv(),
+	})
+}
+
+// readinessCheck 就绪检查接口，依赖异常或服务正在退出时返回 503
+func readinessCheck(c *gin.Context) {
+	if IsShuttingDown() {
+		c.JSON(http.StatusServiceUnavailable, &HealthReport{
+			Status: HealthStatusDown,
+			Env:    GetEnv(),
+		})
+		return
+	}
+	report := CheckHealth(c.Request.Context())
+	status := http.StatusOK
+	if report.Status != HealthStatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// registerHealthRouter 注册健康检查路由
+func registerHealthRouter(engine *gin.Engine) {
+	engine.GET("/healthz", healthCheck)
+	engine.GET("/healthz/live", livenessCheck)
+	engine.GET("/healthz/ready", readinessCheck)
+}
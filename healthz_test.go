@@ -0,0 +1,137 @@
+package gohera
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resetHealthCheckers 清空已注册的依赖检查，测试结束后恢复
+func resetHealthCheckers(t *testing.T) {
+	healthMu.Lock()
+	saved := healthCheckers
+	healthCheckers = nil
+	healthMu.Unlock()
+	t.Cleanup(func() {
+		healthMu.Lock()
+		healthCheckers = saved
+		healthMu.Unlock()
+		shuttingDown.Store(false)
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	resetHealthCheckers(t)
+	RegisterHealthChecker("mysql", func(ctx context.Context) error { return nil })
+	RegisterHealthChecker("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+	RegisterHealthChecker("kafka", func(ctx context.Context) error { panic("boom") })
+	RegisterHealthChecker("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}, WithHealthTimeout(10*time.Millisecond))
+
+	report := CheckHealth(context.Background())
+	if report.Status != HealthStatusDown {
+		t.Fatalf("Status = %s, want down", report.Status)
+	}
+	want := map[string]string{
+		"mysql": "",
+		"redis": "connection refused",
+		"kafka": "panic: boom",
+		"slow":  context.DeadlineExceeded.Error(),
+	}
+	for name, msg := range want {
+		r, ok := report.Checks[name]
+		if !ok {
+			t.Fatalf("missing check %s", name)
+		}
+		if r.Error != msg {
+			t.Errorf("%s error = %q, want %q", name, r.Error, msg)
+		}
+		if (msg == "") != (r.Status == HealthStatusUp) {
+			t.Errorf("%s status = %s", name, r.Status)
+		}
+	}
+}
+
+func TestRegisterHealthCheckerReplace(t *testing.T) {
+	resetHealthCheckers(t)
+	RegisterHealthChecker("mysql", func(ctx context.Context) error { return errors.New("down") })
+	RegisterHealthChecker("mysql", func(ctx context.Context) error { return nil })
+	report := CheckHealth(context.Background())
+	if len(report.Checks) != 1 || report.Status != HealthStatusUp {
+		t.Fatalf("report = %+v, want single healthy check", report)
+	}
+}
+
+func TestHealthCheckCache(t *testing.T) {
+	var calls atomic.Int32
+	h := &healthChecker{name: "db", fn: func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}}
+	h.check(context.Background(), time.Minute)
+	h.check(context.Background(), time.Minute)
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("calls = %d, want 1 within cache ttl", n)
+	}
+	h.check(context.Background(), 0)
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("calls = %d, want 2 without cache", n)
+	}
+}
+
+func TestHealthCheckCanceledNotCached(t *testing.T) {
+	h := &healthChecker{name: "db", fn: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if r := h.check(ctx, time.Minute); r.Status != HealthStatusDown {
+		t.Fatalf("canceled check status = %s, want down", r.Status)
+	}
+
+	h.fn = func(ctx context.Context) error { return nil }
+	if r := h.check(context.Background(), time.Minute); r.Status != HealthStatusUp {
+		t.Fatalf("status after canceled check = %s, want up", r.Status)
+	}
+}
+
+func TestReadinessCheck(t *testing.T) {
+	resetHealthCheckers(t)
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	registerHealthRouter(engine)
+	get := func(path string) (int, HealthReport) {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var r HealthReport
+		_ = json.Unmarshal(w.Body.Bytes(), &r)
+		return w.Code, r
+	}
+
+	RegisterHealthChecker("redis", func(ctx context.Context) error { return errors.New("down") })
+	if code, r := get("/healthz/ready"); code != http.StatusServiceUnavailable || r.Checks["redis"].Error != "down" {
+		t.Fatalf("ready = %d %+v, want 503", code, r)
+	}
+	if code, _ := get("/healthz/live"); code != http.StatusOK {
+		t.Fatalf("live = %d, want 200", code)
+	}
+
+	resetHealthCheckers(t)
+	if code, _ := get("/healthz/ready"); code != http.StatusOK {
+		t.Fatalf("ready = %d, want 200", code)
+	}
+	shuttingDown.Store(true)
+	if code, _ := get("/healthz/ready"); code != http.StatusServiceUnavailable {
+		t.Fatalf("ready while shutting down = %d, want 503", code)
+	}
+}
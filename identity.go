@@ -0,0 +1,120 @@
+package gohera
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// principalCtx 用户身份在 Context 中的键
+const principalCtx = "principal-ctx"
+
+// Principal 当前请求的用户身份
+type Principal struct {
+	UserId string         `json:"user_id"`
+	Name   string         `json:"name"`
+	Roles  []string       `json:"roles"`
+	Method string         `json:"method"` // 认证方式，如 jwt
+	Claims map[string]any `json:"claims"` // 认证方式附带的信息，如 JWT 的 claims
+}
+
+// HasRole 是否拥有角色
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator 从请求中识别用户身份
+type Authenticator interface {
+	// Authenticate 请求未携带凭证时返回 nil, nil，凭证无效时返回错误
+	Authenticate(c *gin.Context) (*Principal, error)
+}
+
+// AuthenticatorFunc 函数形式的 Authenticator
+type AuthenticatorFunc func(c *gin.Context) (*Principal, error)
+
+// Authenticate 实现 Authenticator 接口
+func (f AuthenticatorFunc) Authenticate(c *gin.Context) (*Principal, error) {
+	return f(c)
+}
+
+// Identity 识别用户身份的中间件，需要在 TraceContext 之后使用
+// 依次使用 authenticators 识别，第一个返回用户身份的生效；凭证无效时以 ErrAccessToken 结束请求，未携带凭证时作为匿名用户继续处理
+func Identity(authenticators ...Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, a := range authenticators {
+			p, err := a.Authenticate(c)
+			if err != nil {
+				// 失败原因只记录到日志，不返回给客户端
+				Warntf(c, "authenticate fail: %v", err)
+				JsonAbort(c, ErrAccessToken, "")
+				return
+			}
+			if p != nil {
+				SetPrincipal(c, p)
+				break
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireUser 要求已识别用户身份的中间件，需要在 Identity 之后使用，匿名用户以 ErrAccessToken 结束请求
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if CurrentUser(c) == nil {
+			JsonAbort(c, ErrAccessToken, "")
+			return
+		}
+		c.Next()
+	}
+}
+
+// SetPrincipal 设置当前请求的用户身份，之后的日志及 Span 中包含用户 ID
+// Trace 可能正被其他协程读取，复制后写入用户 ID，不修改原有的 Trace
+func SetPrincipal(c *gin.Context, p *Principal) {
+	c.Set(principalCtx, p)
+	ctx := context.WithValue(c.Request.Context(), principalCtx, p)
+	if t := GetTraceContext(c); t.TraceId != "" {
+		nt := *t
+		nt.UserId = p.UserId
+		c.Set(TraceCtx, &nt)
+		ctx = context.WithValue(ctx, TraceCtx, &nt)
+	}
+	c.Request = c.Request.WithContext(ctx)
+	if s := SpanFromContext(c); s != nil {
+		s.SetAttribute("enduser.id", p.UserId)
+	}
+}
+
+// WithPrincipal 返回带有用户身份的 Context，用于定时任务、消息消费等非 HTTP 场景
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, principalCtx, p)
+}
+
+// CurrentUser 获取当前的用户身份，匿名用户返回 nil
+func CurrentUser(ctx context.Context) *Principal {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(principalCtx).(*Principal)
+	return p
+}
+
+// CurrentUserId 获取当前的用户 ID，匿名用户返回空字符串
+func CurrentUserId(ctx context.Context) string {
+	if p := CurrentUser(ctx); p != nil {
+		return p.UserId
+	}
+	return ""
+}
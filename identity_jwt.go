@@ -0,0 +1,280 @@
+package gohera
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/metlive/gohera/jwt"
+)
+
+// jwtConfig [jwt] 配置
+type jwtConfig struct {
+	Algorithms    []string       `mapstructure:"algorithms" binding:"dive,oneof=HS256 RS256 ES256"` // 允许的签名算法
+	Secret        string         `mapstructure:"secret"`                                            // HS256 密钥，用于未指定 kid 的 token
+	PublicKey     string         `mapstructure:"public_key"`                                        // RS256、ES256 的 PEM 公钥或证书，用于未指定 kid 的 token
+	PublicKeyFile string         `mapstructure:"public_key_file"`                                   // 同 public_key，从文件读取
+	Keys          []jwtKeyConfig `mapstructure:"keys" binding:"dive"`                               // 按 kid 区分的密钥，用于密钥轮转
+	JWKS          string         `mapstructure:"jwks"`                                              // JWKS 的 URL 或文件路径
+	JWKSRefresh   time.Duration  `mapstructure:"jwks_refresh" binding:"gte=0"`                      // JWKS 定时刷新的间隔，遇到未知 kid 时最多每分钟刷新一次
+	JWKSInsecure  bool           `mapstructure:"jwks_insecure"`                                     // 允许使用 http 的 JWKS URL，默认只允许 https
+	Issuer        string         `mapstructure:"issuer"`                                            // 要求的 iss，为空时不验证
+	Audience      []string       `mapstructure:"audience"`                                          // aud 需要包含其中之一，为空时不验证
+	Leeway        time.Duration  `mapstructure:"leeway" binding:"gte=0"`                            // 验证 exp、nbf 时允许的时钟偏差
+	RequireExp    bool           `mapstructure:"require_exp"`                                       // 要求 token 包含 exp
+	TokenLookup   []string       `mapstructure:"token_lookup"`                                      // 获取 token 的位置，按顺序查找，如 header:Authorization、cookie:token、query:token
+	SkipPaths     []string       `mapstructure:"skip_paths"`                                        // 不需要验证的路径，以 * 结尾时按前缀匹配
+	Optional      bool           `mapstructure:"optional"`                                          // 未携带 token 时作为匿名用户继续处理
+	UserIdClaim   string         `mapstructure:"user_id_claim"`                                     // 用户 ID 所在的 claim
+	NameClaim     string         `mapstructure:"name_claim"`                                        // 用户名所在的 claim
+	RolesClaim    string         `mapstructure:"roles_claim"`                                       // 角色所在的 claim，支持字符串及数组
+}
+
+// jwtKeyConfig [[jwt.keys]] 配置
+type jwtKeyConfig struct {
+	Kid string `mapstructure:"kid" binding:"required"`
+	Key string `mapstructure:"key" binding:"required"` // PEM 公钥或证书，其他内容作为 HS256 密钥
+}
+
+// defaultJWTConfig 默认 JWT 配置
+func defaultJWTConfig() jwtConfig {
+	return jwtConfig{
+		Algorithms:  []string{jwt.HS256, jwt.RS256, jwt.ES256},
+		JWKSRefresh: 10 * time.Minute,
+		Leeway:      30 * time.Second,
+		RequireExp:  true,
+		TokenLookup: []string{"header:Authorization"},
+		SkipPaths:   []string{"/healthz", "/healthz/*"},
+		UserIdClaim: "sub",
+		NameClaim:   "name",
+		RolesClaim:  "roles",
+	}
+}
+
+// jwtVerifier 根据 [jwt] 配置创建的验证器
+type jwtVerifier struct {
+	cfg    jwtConfig
+	parser *jwt.Parser
+	keys   jwt.KeySet
+	jwks   *jwt.JWKS
+}
+
+// jwtState 当前使用的验证器，未配置 [jwt] 时为 nil
+var jwtState atomic.Pointer[jwtVerifier]
+
+// loadJWTConfig 加载 [jwt] 配置
+func loadJWTConfig() (jwtConfig, error) {
+	cfg := defaultJWTConfig()
+	if err := UnmarshalKey("jwt", &cfg); err != nil {
+		return cfg, fmt.Errorf("jwt config parse fail: %w", err)
+	}
+	return cfg, nil
+}
+
+// newJWTVerifier 根据配置创建验证器，配置了 JWKS 时首次加载失败返回错误
+func newJWTVerifier(cfg jwtConfig) (*jwtVerifier, error) {
+	for _, l := range cfg.TokenLookup {
+		source, name, _ := strings.Cut(l, ":")
+		if name == "" || (source != "header" && source != "cookie" && source != "query") {
+			return nil, fmt.Errorf("jwt.token_lookup %q is invalid", l)
+		}
+	}
+	static := &jwt.StaticKeys{Kids: make(map[string]any)}
+	if cfg.Secret != "" {
+		static.Default = append(static.Default, []byte(cfg.Secret))
+	}
+	pub := cfg.PublicKey
+	if cfg.PublicKeyFile != "" {
+		b, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt.public_key_file read fail: %w", err)
+		}
+		pub = string(b)
+	}
+	if pub != "" {
+		key, err := jwt.ParsePublicKey([]byte(pub))
+		if err != nil {
+			return nil, fmt.Errorf("jwt.public_key: %w", err)
+		}
+		// secret 与 public_key 同时配置时，按 token 的算法选择
+		static.Default = append(static.Default, key)
+	}
+	for _, k := range cfg.Keys {
+		if strings.HasPrefix(strings.TrimSpace(k.Key), "-----BEGIN") {
+			key, err := jwt.ParsePublicKey([]byte(k.Key))
+			if err != nil {
+				return nil, fmt.Errorf("jwt.keys %s: %w", k.Kid, err)
+			}
+			static.Kids[k.Kid] = key
+		} else {
+			static.Kids[k.Kid] = []byte(k.Key)
+		}
+	}
+	v := &jwtVerifier{
+		cfg: cfg,
+		parser: &jwt.Parser{
+			Algorithms: cfg.Algorithms,
+			Issuer:     cfg.Issuer,
+			Audience:   cfg.Audience,
+			Leeway:     cfg.Leeway,
+			AllowNoExp: !cfg.RequireExp,
+		},
+	}
+	keys := jwt.MultiKeySet{static}
+	if cfg.JWKS != "" {
+		jwks, err := jwt.NewJWKS(cfg.JWKS, jwt.JWKSOptions{
+			Refresh:  cfg.JWKSRefresh,
+			Insecure: cfg.JWKSInsecure,
+			OnError: func(err error) {
+				Named("jwt").Warntf(context.Background(), "%v", err)
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		v.jwks = jwks
+		keys = append(keys, jwks)
+	} else if len(static.Default) == 0 && len(static.Kids) == 0 {
+		return nil, errors.New("jwt: one of secret, public_key, public_key_file, keys or jwks is required")
+	}
+	v.keys = keys
+	return v, nil
+}
+
+// close 停止 JWKS 的定时刷新
+func (v *jwtVerifier) close() {
+	if v != nil && v.jwks != nil {
+		v.jwks.Close()
+	}
+}
+
+// applyJWTConfig 应用 [jwt] 配置，未配置时不启用
+func applyJWTConfig() error {
+	var v *jwtVerifier
+	if IsSet("jwt") {
+		cfg, err := loadJWTConfig()
+		if err != nil {
+			return err
+		}
+		if v, err = newJWTVerifier(cfg); err != nil {
+			return err
+		}
+	}
+	jwtState.Swap(v).close()
+	return nil
+}
+
+// initJWT 根据 [jwt] 配置初始化 JWT 验证，配置热更新时重新应用，失败时保留原有的配置
+func initJWT() error {
+	if err := applyJWTConfig(); err != nil {
+		return err
+	}
+	OnChange("jwt", func(_, _ any) {
+		if err := applyJWTConfig(); err != nil {
+			Errortf(context.Background(), "jwt config fail: %v", err)
+		}
+	})
+	OnShutdown("jwt", func(ctx context.Context) error {
+		jwtState.Swap(nil).close()
+		return nil
+	})
+	return nil
+}
+
+// token 按 token_lookup 的顺序获取 token，请求头中的 Bearer 前缀会被去除
+func (v *jwtVerifier) token(c *gin.Context) string {
+	for _, l := range v.cfg.TokenLookup {
+		source, name, _ := strings.Cut(l, ":")
+		var token string
+		switch source {
+		case "header":
+			token = c.GetHeader(name)
+			if len(token) > 7 && strings.EqualFold(token[:7], "Bearer ") {
+				token = token[7:]
+			}
+		case "cookie":
+			token, _ = c.Cookie(name)
+		case "query":
+			token = c.Query(name)
+		}
+		if token = strings.TrimSpace(token); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// authenticate 验证 token 并转换为用户身份
+func (v *jwtVerifier) authenticate(c *gin.Context) (*Principal, error) {
+	token := v.token(c)
+	if token == "" {
+		return nil, nil
+	}
+	t, err := v.parser.Parse(token, v.keys)
+	if err != nil {
+		return nil, err
+	}
+	userId := t.Claims.String(v.cfg.UserIdClaim)
+	if userId == "" {
+		return nil, fmt.Errorf("jwt: claim %s is missing", v.cfg.UserIdClaim)
+	}
+	return &Principal{
+		UserId: userId,
+		Name:   t.Claims.String(v.cfg.NameClaim),
+		Roles:  t.Claims.Strings(v.cfg.RolesClaim),
+		Method: "jwt",
+		Claims: t.Claims,
+	}, nil
+}
+
+// JWTAuthenticator 按 [jwt] 配置验证 token 的 Authenticator，与 Identity 一起使用
+// skip_paths 中的路径及未携带 token 的请求返回 nil, nil；未配置 [jwt] 时所有请求均验证失败
+func JWTAuthenticator() Authenticator {
+	return AuthenticatorFunc(func(c *gin.Context) (*Principal, error) {
+		v := jwtState.Load()
+		if v == nil {
+			return nil, errors.New("jwt: [jwt] is not configured")
+		}
+		if matchPath(v.cfg.SkipPaths, c.Request.URL.Path) {
+			return nil, nil
+		}
+		return v.authenticate(c)
+	})
+}
+
+// JWTAuth 按 [jwt] 配置验证 token 的中间件，需要在 TraceContext 之后使用
+// 验证通过后设置用户身份；token 缺失 (optional 为 false 时)、无效或未配置 [jwt] 时以 ErrAccessToken 结束请求，skip_paths 中的路径不验证
+func JWTAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v := jwtState.Load()
+		if v == nil {
+			Errortf(c, "jwt auth fail: [jwt] is not configured")
+			JsonAbort(c, ErrAccessToken, "")
+			return
+		}
+		if matchPath(v.cfg.SkipPaths, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		p, err := v.authenticate(c)
+		if err != nil {
+			// 失败原因只记录到日志，不返回给客户端
+			Warntf(c, "jwt auth fail: %v", err)
+			JsonAbort(c, ErrAccessToken, "")
+			return
+		}
+		if p == nil && !v.cfg.Optional {
+			JsonAbort(c, ErrAccessToken, "")
+			return
+		}
+		if p != nil {
+			SetPrincipal(c, p)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,140 @@
+package gohera
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/metlive/gohera/jwt"
+)
+
+const testJWTSecret = "jwt-secret"
+
+// setTestJWT 使用 cfg 创建验证器作为当前验证器，测试结束后恢复
+func setTestJWT(t *testing.T, cfg jwtConfig) {
+	v, err := newJWTVerifier(cfg)
+	if err != nil {
+		t.Fatalf("newJWTVerifier() error = %v", err)
+	}
+	saved := jwtState.Swap(v)
+	t.Cleanup(func() {
+		jwtState.Swap(saved).close()
+	})
+}
+
+// newJWTEngine 创建注册了 TraceContext 及 JWTAuth 的引擎，/user 返回当前用户 ID
+func newJWTEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(TraceContext(), JWTAuth())
+	engine.GET("/user", func(c *gin.Context) {
+		c.String(http.StatusOK, CurrentUserId(c))
+	})
+	engine.GET("/healthz", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return engine
+}
+
+// jwtRequest 携带 token 请求 path
+func jwtRequest(engine *gin.Engine, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+// signTestJWT 使用 testJWTSecret 签发 token
+func signTestJWT(t *testing.T, claims jwt.Claims) string {
+	t.Helper()
+	token, err := jwt.Sign(jwt.HS256, "", claims, []byte(testJWTSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestJWTAuth(t *testing.T) {
+	observeLogs(t)
+	cfg := defaultJWTConfig()
+	cfg.Secret = testJWTSecret
+	setTestJWT(t, cfg)
+	engine := newJWTEngine()
+	exp := time.Now().Add(time.Hour).Unix()
+
+	if w := jwtRequest(engine, "/user", signTestJWT(t, jwt.Claims{"sub": "u-1", "exp": exp})); w.Code != http.StatusOK || w.Body.String() != "u-1" {
+		t.Fatalf("response = %d %s", w.Code, w.Body.String())
+	}
+	for name, token := range map[string]string{
+		"missing token": "",
+		"missing exp":   signTestJWT(t, jwt.Claims{"sub": "u-1"}),
+		"missing sub":   signTestJWT(t, jwt.Claims{"exp": exp}),
+		"expired":       signTestJWT(t, jwt.Claims{"sub": "u-1", "exp": time.Now().Add(-time.Hour).Unix()}),
+	} {
+		if code := responseCode(t, jwtRequest(engine, "/user", token)); code != ErrAccessToken {
+			t.Errorf("%s: code = %d, want %d", name, code, ErrAccessToken)
+		}
+	}
+	if w := jwtRequest(engine, "/healthz", ""); w.Code != http.StatusOK {
+		t.Fatalf("skip path status = %d", w.Code)
+	}
+}
+
+func TestJWTAuthRequireExp(t *testing.T) {
+	observeLogs(t)
+	loadTestConfig(t, "", map[string]string{"app.toml": "[jwt]\nsecret = \"" + testJWTSecret + "\"\nrequire_exp = false\noptional = true\n"})
+	cfg, err := loadJWTConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RequireExp || !defaultJWTConfig().RequireExp {
+		t.Fatalf("require_exp = %v, want false from config and true by default", cfg.RequireExp)
+	}
+	setTestJWT(t, cfg)
+	engine := newJWTEngine()
+
+	if w := jwtRequest(engine, "/user", signTestJWT(t, jwt.Claims{"sub": "u-1"})); w.Code != http.StatusOK || w.Body.String() != "u-1" {
+		t.Fatalf("response = %d %s", w.Code, w.Body.String())
+	}
+	// optional 时未携带 token 作为匿名用户
+	if w := jwtRequest(engine, "/user", ""); w.Code != http.StatusOK || w.Body.String() != "" {
+		t.Fatalf("anonymous response = %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestNewJWTVerifierJWKS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"keys":[{"kty":"oct","kid":"hs","k":"c2VjcmV0"}]}`))
+	}))
+	defer srv.Close()
+
+	cfg := defaultJWTConfig()
+	cfg.JWKS = srv.URL
+	if _, err := newJWTVerifier(cfg); err == nil || !strings.Contains(err.Error(), "must use https") {
+		t.Fatalf("newJWTVerifier() error = %v, want https required", err)
+	}
+	// jwks_insecure 允许 http，但 URL 中的 oct 密钥不可用
+	cfg.JWKSInsecure = true
+	if _, err := newJWTVerifier(cfg); err == nil || !strings.Contains(err.Error(), "no usable keys") {
+		t.Fatalf("newJWTVerifier() error = %v, want no usable keys", err)
+	}
+}
+
+func TestNewJWTVerifierInvalid(t *testing.T) {
+	for name, cfg := range map[string]jwtConfig{
+		"no key":       {},
+		"token lookup": {Secret: "s", TokenLookup: []string{"body:token"}},
+		"public key":   {PublicKey: "not a pem"},
+		"kid key":      {Keys: []jwtKeyConfig{{Kid: "a", Key: "-----BEGIN PUBLIC KEY-----\n-----END PUBLIC KEY-----"}}},
+	} {
+		if _, err := newJWTVerifier(cfg); err == nil {
+			t.Errorf("%s: newJWTVerifier() should fail", name)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package gohera
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testAuthenticator 按 Authorization 请求头识别用户，值为 bad 时返回错误
+var testAuthenticator = AuthenticatorFunc(func(c *gin.Context) (*Principal, error) {
+	switch token := c.GetHeader("Authorization"); token {
+	case "":
+		return nil, nil
+	case "bad":
+		return nil, errors.New("invalid token")
+	default:
+		return &Principal{UserId: token, Roles: []string{"admin"}, Method: "test"}, nil
+	}
+})
+
+// newIdentityEngine 创建注册了 TraceContext 及 Identity 的引擎，handler 处理 /user 请求
+func newIdentityEngine(handler gin.HandlerFunc, middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(TraceContext())
+	engine.Use(middlewares...)
+	engine.Use(Identity(AuthenticatorFunc(func(*gin.Context) (*Principal, error) {
+		return nil, nil
+	}), testAuthenticator))
+	engine.GET("/user", handler)
+	engine.GET("/admin", RequireUser(), handler)
+	return engine
+}
+
+// responseCode 解析响应中的错误码
+func responseCode(t *testing.T, w *httptest.ResponseRecorder) int {
+	t.Helper()
+	var rsp httpResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &rsp); err != nil {
+		t.Fatalf("invalid response %s: %v", w.Body.String(), err)
+	}
+	return rsp.Code
+}
+
+func TestIdentity(t *testing.T) {
+	logs := observeLogs(t)
+	var original *Trace
+	engine := newIdentityEngine(func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if CurrentUserId(c) != "u-1" || CurrentUserId(ctx) != "u-1" || !CurrentUser(c).HasRole("admin") {
+			t.Errorf("current user = %+v", CurrentUser(c))
+		}
+		if GetTraceContext(c).UserId != "u-1" || GetTraceContext(ctx).UserId != "u-1" {
+			t.Errorf("trace user id = %q, %q", GetTraceContext(c).UserId, GetTraceContext(ctx).UserId)
+		}
+		Info(c, "handled")
+		c.Status(http.StatusOK)
+	}, func(c *gin.Context) {
+		original = GetTraceContext(c)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/user", nil)
+	req.Header.Set("Authorization", "u-1")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if m := logs.TakeAll()[0].ContextMap(); m["x_user_id"] != "u-1" {
+		t.Fatalf("x_user_id = %v", m["x_user_id"])
+	}
+	// 不修改中间件创建的 Trace
+	if original == nil || original.TraceId == "" || original.UserId != "" {
+		t.Fatalf("original trace = %+v", original)
+	}
+}
+
+func TestIdentityAnonymousAndInvalid(t *testing.T) {
+	observeLogs(t)
+	engine := newIdentityEngine(func(c *gin.Context) {
+		if CurrentUser(c) != nil || CurrentUserId(c) != "" || GetTraceContext(c).UserId != "" {
+			t.Errorf("anonymous user = %+v", CurrentUser(c))
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("anonymous status = %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+	if code := responseCode(t, w); code != ErrAccessToken {
+		t.Fatalf("RequireUser code = %d, want %d", code, ErrAccessToken)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/user", nil)
+	req.Header.Set("Authorization", "bad")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if code := responseCode(t, w); code != ErrAccessToken {
+		t.Fatalf("invalid token code = %d, want %d", code, ErrAccessToken)
+	}
+}
+
+func TestSetPrincipalConcurrentTraceRead(t *testing.T) {
+	observeLogs(t)
+	var wg sync.WaitGroup
+	engine := newIdentityEngine(func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}, func(c *gin.Context) {
+		// 识别用户前启动的协程继续使用原有的 Trace，go test -race 时检查数据竞争
+		ctx := c.Request.Context()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				Info(ctx, "background")
+			}
+		}()
+	})
+	req := httptest.NewRequest(http.MethodGet, "/user", nil)
+	req.Header.Set("Authorization", "u-1")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	wg.Wait()
+}
+
+func TestWithPrincipal(t *testing.T) {
+	ctx := WithPrincipal(nil, &Principal{UserId: "job"})
+	if CurrentUserId(ctx) != "job" {
+		t.Fatalf("CurrentUserId() = %q", CurrentUserId(ctx))
+	}
+	if id := fieldString(getContextFields(ctx), "x_user_id"); id != "job" {
+		t.Fatalf("x_user_id = %q", id)
+	}
+	var p *Principal
+	if CurrentUser(nil) != nil || CurrentUserId(context.Background()) != "" || p.HasRole("admin") {
+		t.Fatal("anonymous user should have no identity")
+	}
+}
@@ -1,6 +1,7 @@
 package gohera
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -19,83 +20,145 @@ const DefaultLogPath = "/var/log/trace"
 
 // InitApp 初始化应用
 // 解析环境变量、配置文件、日志、数据库（MySQL/Redis）、PProf 和验证器，并返回 Gin 引擎
+// 初始化失败时会 panic，需要自行处理错误时请使用 New
 func InitApp() (router *gin.Engine) {
-	flag.Parse()
-
-	// 解析环境变量
-	err := parseEnv(*env)
+	app, err := New()
 	if err != nil {
-		panic(fmt.Errorf("env parse fail ：  %s \n", err))
+		panic(err)
 	}
+	return app.Engine
+}
 
-	// 初始化应用配置
-	err = initAppConfig()
+// initLogger 根据 [log] 配置初始化日志处理器
+func initLogger() error {
+	cfg, err := loadLoggerConfig()
 	if err != nil {
-		panic(fmt.Errorf("init config fail ：  %s \n", err))
+		return err
 	}
-
-	// 初始化日志处理器
-	appPath := GetDefaultString("log.path", DefaultLogPath)
-	initLoggerPool(loggerConfig{
-		FilePath:   appPath + "/" + appName,
-		MaxSize:    0,
-		MaxBackups: 0,
-		Compress:   false,
-		Mode:       "",
+	if err = applyLogLevelConfig(cfg); err != nil {
+		return err
+	}
+	if err = applyMaskConfig(cfg.Mask); err != nil {
+		return err
+	}
+	accessLogConf.Store(&cfg.Access)
+	applyDbLogConfig(cfg)
+	if err = applySamplingConfig(cfg.Sampling); err != nil {
+		return err
+	}
+	if err = applyAlertConfig(cfg.Alert); err != nil {
+		return err
+	}
+	if err = initLoggerPool(cfg); err != nil {
+		return fmt.Errorf("init logger fail: %w", err)
+	}
+	watchLogLevelConfig()
+	watchMaskConfig()
+	watchAccessLogConfig()
+	watchSamplingConfig()
+	watchAlertConfig()
+	watchDbLogConfig()
+	// 日志最先注册，退出时最后刷新，保证其他钩子的日志能够落盘
+	OnShutdown("logger", func(ctx context.Context) error {
+		// 输出尚未输出的重复日志汇总
+		logSampler.Swap(nil).close()
+		// 发送尚未发送的告警
+		logAlerter.Swap(nil).close()
+		// 控制台输出的 Sync 在部分系统上会返回 invalid argument，这里忽略错误
+		_ = logger.Sync()
+		_ = accessLogger.Sync()
+		return nil
 	})
+	return nil
+}
 
-	// mysql初始化
-	if IsSet("mysql") {
-		dbList := GetStringMap("mysql")
-		for key := range dbList {
-			if IsSet("mysql." + key) {
-				conf := new(mysql.Config)
-				if err = UnmarshalKey("mysql."+key, conf); err != nil {
-					panic(fmt.Errorf("unable to decode dbConfig struct：  %s \n pid:%d", err, os.Getpid()))
-				}
-				Mysql[key] = func(conf *mysql.Config) *mysql.DB {
-					conf.Env = GetEnv()
-					imysql, err := mysql.InitOnce(conf).Connect()
-					if err != nil {
-						panic(fmt.Errorf("unable to connect fail ：  %s \n", err))
-					}
-					return imysql
-				}(conf)
-			}
-		}
+// initMysql 根据 mysql 配置初始化所有数据库连接
+func initMysql() error {
+	if !IsSet("mysql") {
+		return nil
 	}
-
-	// redis初始化
-	if IsSet("redis") {
-		conf := new(redis.Config)
-		if err = UnmarshalKey("redis", conf); err != nil {
-			panic(fmt.Errorf("unable to decode dbConfig struct：  %s \n pid:%d", err, os.Getpid()))
+	dbList := GetStringMap("mysql")
+	for key := range dbList {
+		if !IsSet("mysql." + key) {
+			continue
+		}
+		conf := new(mysql.Config)
+		if err := UnmarshalKey("mysql."+key, conf); err != nil {
+			return fmt.Errorf("unable to decode dbConfig struct: %w, pid:%d", err, os.Getpid())
 		}
-		Redis, err = redis.New(conf)
+		conf.Env = GetEnv()
+		db, err := mysql.InitOnce(conf).Connect()
 		if err != nil {
-			panic(fmt.Errorf("unable to connect fail ：  %s \n", err))
+			return fmt.Errorf("unable to connect mysql.%s: %w", key, err)
 		}
+		Mysql[key] = db
+		RegisterHealthChecker("mysql."+key, db.PingContext)
+		OnShutdown("mysql."+key, func(ctx context.Context) error {
+			return db.Close()
+		})
+	}
+	return nil
+}
+
+// initRedis 根据 redis 配置初始化客户端
+func initRedis() error {
+	if !IsSet("redis") {
+		return nil
 	}
+	conf := new(redis.Config)
+	if err := UnmarshalKey("redis", conf); err != nil {
+		return fmt.Errorf("unable to decode redisConfig struct: %w, pid:%d", err, os.Getpid())
+	}
+	client, err := redis.New(conf)
+	if err != nil {
+		return fmt.Errorf("unable to connect redis: %w", err)
+	}
+	Redis = client
+	RegisterHealthChecker("redis", client.Ping)
+	OnShutdown("redis", func(ctx context.Context) error {
+		return client.Close()
+	})
+	return nil
+}
 
+// newEngine 创建 Gin 引擎并注册中间件、默认路由、PProf 和验证器
+func newEngine(o *options) *gin.Engine {
 	engine := gin.New()
-	// 初始化上下文
-	engine.Use(TraceContext())
-	// 异常捕获
-	if !IsDev() {
-		engine.Use(HandlerRecovery(true))
-	}
-	// 记录请求日志
-	registerRouter(engine)
+	if o.setMiddleware {
+		engine.Use(o.middlewares...)
+	} else {
+		// 初始化上下文
+		engine.Use(TraceContext())
+		// 记录请求日志，在异常捕获之前以记录 panic 后的状态码
+		engine.Use(HandleAppAccessLog())
+		// 异常捕获
+		if !IsDev() {
+			engine.Use(HandlerRecovery(true))
+		}
+	}
+	if !o.skipRoutes {
+		registerRouter(engine)
+	}
 
-	// 是否需要开启pprof
-	prof := GetInt("zhttp.pprof")
-	if prof == 1 {
+	// 是否需要开启pprof，开启运维端口时 pprof 只在运维端口上提供
+	// 兼容早期版本读取的 zhttp.pprof
+	prof := GetInt("http.pprof")
+	if !IsSet("http.pprof") {
+		prof = GetInt("zhttp.pprof")
+	}
+	if prof == 1 && !AdminEnabled() {
 		pprof.Register(engine)
 	}
 
 	// 数字不要解析成float64
 	binding.EnableDecoderUseNumber = true
 	// 注册自定义参数验证
-	binding.Validator = new(validator.DefaultValidator)
+	if o.setValidator {
+		if o.validator != nil {
+			binding.Validator = o.validator
+		}
+	} else {
+		binding.Validator = new(validator.DefaultValidator)
+	}
 	return engine
 }
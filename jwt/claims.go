@@ -0,0 +1,94 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Claims JWT 的 payload，解析得到的数字为 json.Number
+type Claims map[string]any
+
+// String 获取字符串类型的 claim，数字转换为字符串，不存在时返回空字符串
+func (c Claims) String(key string) string {
+	switch v := c[key].(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Strings 获取字符串数组类型的 claim，单个字符串视为只有一个元素的数组
+func (c Claims) Strings(key string) []string {
+	switch v := c[key].(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// Subject sub
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// Issuer iss
+func (c Claims) Issuer() string {
+	return c.String("iss")
+}
+
+// Audience aud，支持字符串及数组
+func (c Claims) Audience() []string {
+	return c.Strings("aud")
+}
+
+// ExpiresAt exp，不存在时返回零值
+func (c Claims) ExpiresAt() time.Time {
+	t, _ := c.time("exp")
+	return t
+}
+
+// NotBefore nbf，不存在时返回零值
+func (c Claims) NotBefore() time.Time {
+	t, _ := c.time("nbf")
+	return t
+}
+
+// time 获取秒级时间戳类型的 claim，支持小数
+func (c Claims) time(key string) (time.Time, bool) {
+	var f float64
+	switch v := c[key].(type) {
+	case json.Number:
+		n, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		f = n
+	case float64:
+		f = v
+	case int64:
+		f = float64(v)
+	case int:
+		f = float64(v)
+	default:
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)), true
+}
@@ -0,0 +1,278 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JWKSOptions JWKS 的刷新选项
+type JWKSOptions struct {
+	Refresh     time.Duration // 定时刷新的间隔，0 表示不定时刷新
+	MissRefresh time.Duration // 遇到未知 kid 时立即刷新的最小间隔，默认 1 分钟
+	Timeout     time.Duration // 请求 URL 的超时时间，默认 10 秒
+	Insecure    bool          // 允许使用 http URL，默认只允许 https，避免密钥在传输中被替换
+	OnError     func(error)   // 刷新失败时调用，失败时继续使用缓存的密钥
+}
+
+// JWKS 从 JWKS 文件或 URL 加载的密钥，定时刷新以支持密钥轮转
+type JWKS struct {
+	source string
+	opts   JWKSOptions
+	client *http.Client
+
+	mu       sync.RWMutex
+	keys     []jwkKey
+	lastLoad time.Time
+
+	refreshMu sync.Mutex
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// jwkKey 解析后的 JWK
+type jwkKey struct {
+	kid string
+	alg string
+	key any
+}
+
+// NewJWKS 加载 JWKS，source 为 https URL 或文件路径，首次加载失败时返回错误
+// 从 URL 加载时忽略 oct (HS256) 密钥，对称密钥可以签发 token，不应通过网络公开
+func NewJWKS(source string, opts JWKSOptions) (*JWKS, error) {
+	if strings.HasPrefix(source, "http://") && !opts.Insecure {
+		return nil, fmt.Errorf("jwt: jwks %s must use https", source)
+	}
+	if opts.MissRefresh <= 0 {
+		opts.MissRefresh = time.Minute
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	j := &JWKS{
+		source: source,
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if err := j.Refresh(); err != nil {
+		return nil, err
+	}
+	if opts.Refresh > 0 {
+		go j.run()
+	} else {
+		close(j.done)
+	}
+	return j, nil
+}
+
+// Keys 实现 KeySet 接口，kid 不存在时按 MissRefresh 间隔重新加载，以便及时获取新增的密钥
+func (j *JWKS) Keys(kid, alg string) []any {
+	list := j.find(kid, alg)
+	if len(list) > 0 || kid == "" {
+		return list
+	}
+	j.mu.RLock()
+	stale := time.Since(j.lastLoad) >= j.opts.MissRefresh
+	j.mu.RUnlock()
+	if stale {
+		if err := j.Refresh(); err != nil && j.opts.OnError != nil {
+			j.opts.OnError(err)
+		}
+		list = j.find(kid, alg)
+	}
+	return list
+}
+
+// find 在缓存中查找密钥
+func (j *JWKS) find(kid, alg string) []any {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	var list []any
+	for _, k := range j.keys {
+		if kid != "" && k.kid != kid {
+			continue
+		}
+		if (k.alg == "" || k.alg == alg) && matchAlg(k.key, alg) {
+			list = append(list, k.key)
+		}
+	}
+	return list
+}
+
+// Refresh 重新加载 JWKS，失败时保留原有的密钥
+func (j *JWKS) Refresh() error {
+	// 同一时间只加载一次，等待中的调用在加载完成后直接返回
+	j.mu.RLock()
+	last := j.lastLoad
+	j.mu.RUnlock()
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+	j.mu.RLock()
+	loaded := j.lastLoad != last
+	j.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	data, err := j.read()
+	var keys []jwkKey
+	if err == nil {
+		keys, err = parseJWKS(data, !j.remote())
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	// 失败时同样更新加载时间，避免未知 kid 频繁触发加载
+	j.lastLoad = time.Now()
+	if err != nil {
+		return fmt.Errorf("jwt: load jwks %s fail: %w", j.source, err)
+	}
+	j.keys = keys
+	return nil
+}
+
+// Close 停止定时刷新
+func (j *JWKS) Close() {
+	j.closeOnce.Do(func() {
+		close(j.stop)
+	})
+	<-j.done
+}
+
+// run 定时刷新
+func (j *JWKS) run() {
+	defer close(j.done)
+	ticker := time.NewTicker(j.opts.Refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := j.Refresh(); err != nil && j.opts.OnError != nil {
+				j.opts.OnError(err)
+			}
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+// remote 是否从 URL 加载
+func (j *JWKS) remote() bool {
+	return strings.HasPrefix(j.source, "http://") || strings.HasPrefix(j.source, "https://")
+}
+
+// read 读取 JWKS 文件或请求 URL
+func (j *JWKS) read() ([]byte, error) {
+	if !j.remote() {
+		return os.ReadFile(j.source)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), j.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	rsp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", rsp.StatusCode)
+	}
+	// JWKS 通常只有几 KB，限制大小避免异常响应占用内存
+	return io.ReadAll(io.LimitReader(rsp.Body, 1<<20))
+}
+
+// jwk JWKS 中的单个密钥
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// parseJWKS 解析 JWKS，忽略加密用途及不支持的密钥，allowOct 为 false 时忽略 oct 密钥
+func parseJWKS(data []byte, allowOct bool) ([]jwkKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	keys := make([]jwkKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if (k.Use != "" && k.Use != "sig") || (k.Kty == "oct" && !allowOct) {
+			continue
+		}
+		key, err := k.parse()
+		if err != nil {
+			continue
+		}
+		keys = append(keys, jwkKey{kid: k.Kid, alg: k.Alg, key: key})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no usable keys")
+	}
+	return keys, nil
+}
+
+// parse 将 JWK 转换为验证签名的密钥
+func (k *jwk) parse() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exp.IsInt64() || exp.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid rsa key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid ec key")
+		}
+		return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(append([]byte{4}, x...), y...))
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
@@ -0,0 +1,191 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rsaJWK 将 RSA 公钥转换为 JWK
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"alg": RS256,
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// ecJWK 将 P-256 公钥转换为 JWK
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	b, err := key.PublicKey.Bytes()
+	if err != nil {
+		panic(err)
+	}
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(b[1:33]),
+		"y":   base64.RawURLEncoding.EncodeToString(b[33:]),
+	}
+}
+
+// octJWK HS256 密钥的 JWK
+func octJWK(kid string, secret []byte) map[string]string {
+	return map[string]string{"kty": "oct", "kid": kid, "k": base64.RawURLEncoding.EncodeToString(secret)}
+}
+
+// jwksJSON 生成 JWKS 内容
+func jwksJSON(keys ...map[string]string) []byte {
+	b, _ := json.Marshal(map[string]any{"keys": keys})
+	return b
+}
+
+// testJWKSServer 通过 http 提供 JWKS，内容可以随时替换
+type testJWKSServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	body     []byte
+	requests int
+}
+
+func newTestJWKSServer(t *testing.T, body []byte) *testJWKSServer {
+	s := &testJWKSServer{body: body}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests++
+		_, _ = w.Write(s.body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testJWKSServer) set(body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = body
+}
+
+func TestJWKSFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc := octJWK("enc", testSecret)
+	enc["use"] = "enc"
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	if err = os.WriteFile(file, jwksJSON(rsaJWK("rsa", &rsaKey.PublicKey), ecJWK("ec", ecKey), octJWK("hs", testSecret), enc, map[string]string{"kty": "OKP"}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := NewJWKS(file, JWKSOptions{})
+	if err != nil {
+		t.Fatalf("NewJWKS() error = %v", err)
+	}
+	defer jwks.Close()
+
+	// 本地文件中的 oct 密钥可以使用
+	for kid, key := range map[string]any{"rsa": rsaKey, "ec": ecKey, "hs": testSecret} {
+		alg := map[string]string{"rsa": RS256, "ec": ES256, "hs": HS256}[kid]
+		if _, err = testParser().Parse(mustSign(t, alg, kid, testClaims(), key), jwks); err != nil {
+			t.Errorf("%s: Parse() error = %v", kid, err)
+		}
+	}
+	// 加密用途的密钥被忽略
+	if _, err = testParser().Parse(mustSign(t, HS256, "enc", testClaims(), testSecret), jwks); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("enc: Parse() error = %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestJWKSRequireHTTPS(t *testing.T) {
+	if _, err := NewJWKS("http://127.0.0.1:1/jwks.json", JWKSOptions{}); err == nil || !strings.Contains(err.Error(), "must use https") {
+		t.Fatalf("NewJWKS() error = %v, want https required", err)
+	}
+}
+
+func TestJWKSURLIgnoresOct(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestJWKSServer(t, jwksJSON(octJWK("hs", testSecret)))
+	// 只有 oct 密钥时没有可用的密钥
+	if _, err = NewJWKS(srv.URL, JWKSOptions{Insecure: true}); err == nil || !strings.Contains(err.Error(), "no usable keys") {
+		t.Fatalf("NewJWKS() error = %v, want no usable keys", err)
+	}
+
+	srv.set(jwksJSON(octJWK("hs", testSecret), rsaJWK("rsa", &rsaKey.PublicKey)))
+	jwks, err := NewJWKS(srv.URL, JWKSOptions{Insecure: true})
+	if err != nil {
+		t.Fatalf("NewJWKS() error = %v", err)
+	}
+	defer jwks.Close()
+	if _, err = testParser().Parse(mustSign(t, RS256, "rsa", testClaims(), rsaKey), jwks); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if _, err = testParser().Parse(mustSign(t, HS256, "hs", testClaims(), testSecret), jwks); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Parse() error = %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestJWKSUnknownKidRefresh(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestJWKSServer(t, jwksJSON(rsaJWK("old", &oldKey.PublicKey)))
+	var refreshErr error
+	jwks, err := NewJWKS(srv.URL, JWKSOptions{Insecure: true, MissRefresh: time.Millisecond, OnError: func(err error) {
+		refreshErr = err
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jwks.Close()
+
+	// 轮转后遇到未知 kid 时重新加载
+	srv.set(jwksJSON(rsaJWK("old", &oldKey.PublicKey), rsaJWK("new", &newKey.PublicKey)))
+	time.Sleep(2 * time.Millisecond)
+	if _, err = testParser().Parse(mustSign(t, RS256, "new", testClaims(), newKey), jwks); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// 加载失败时继续使用缓存的密钥
+	srv.set([]byte("invalid"))
+	time.Sleep(2 * time.Millisecond)
+	if _, err = testParser().Parse(mustSign(t, RS256, "other", testClaims(), newKey), jwks); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Parse() error = %v, want %v", err, ErrKeyNotFound)
+	}
+	if refreshErr == nil {
+		t.Fatal("OnError was not called")
+	}
+	if _, err = testParser().Parse(mustSign(t, RS256, "old", testClaims(), oldKey), jwks); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if srv.requests != 3 {
+		t.Fatalf("requested %d times, want 3", srv.requests)
+	}
+}
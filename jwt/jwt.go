@@ -0,0 +1,255 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// 支持的签名算法
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+var (
+	ErrMalformed   = errors.New("jwt: malformed token")
+	ErrAlgorithm   = errors.New("jwt: algorithm not allowed")
+	ErrKeyNotFound = errors.New("jwt: key not found")
+	ErrSignature   = errors.New("jwt: invalid signature")
+	ErrExpired     = errors.New("jwt: token is expired")
+	ErrMissingExp  = errors.New("jwt: exp is required")
+	ErrNotValidYet = errors.New("jwt: token is not valid yet")
+	ErrIssuer      = errors.New("jwt: invalid issuer")
+	ErrAudience    = errors.New("jwt: invalid audience")
+)
+
+// Header JWT 头部
+type Header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Token 解析并验证通过的 JWT
+type Token struct {
+	Header Header
+	Claims Claims
+}
+
+// Parser 验证 JWT 的签名及 exp、nbf、iss、aud
+type Parser struct {
+	Algorithms []string         // 允许的签名算法，为空时允许所有支持的算法
+	Issuer     string           // 要求的 iss，为空时不验证
+	Audience   []string         // aud 需要包含其中之一，为空时不验证
+	Leeway     time.Duration    // 验证 exp、nbf 时允许的时钟偏差
+	AllowNoExp bool             // 允许没有 exp 的 token，默认要求 exp，避免签发的 token 永久有效
+	Now        func() time.Time // 当前时间，为 nil 时使用 time.Now
+}
+
+// Parse 解析 token 并验证签名及 claims，签名使用 keys 中与 kid 及算法匹配的密钥
+func (p *Parser) Parse(token string, keys KeySet) (*Token, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	var t Token
+	if err := decodeSegment(parts[0], &t.Header); err != nil {
+		return nil, err
+	}
+	if !p.allow(t.Header.Alg) {
+		return nil, fmt.Errorf("%w: %q", ErrAlgorithm, t.Header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	candidates := keys.Keys(t.Header.Kid, t.Header.Alg)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, t.Header.Kid)
+	}
+	signed := []byte(token[:len(parts[0])+1+len(parts[1])])
+	err = ErrSignature
+	for _, key := range candidates {
+		if err = verify(t.Header.Alg, key, signed, sig); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = decodeSegment(parts[1], &t.Claims); err != nil {
+		return nil, err
+	}
+	if err = p.Validate(t.Claims); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Validate 验证 exp、nbf、iss、aud，AllowNoExp 为 false 时要求 exp
+func (p *Parser) Validate(c Claims) error {
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+	for _, key := range []string{"exp", "nbf"} {
+		if _, ok := c.time(key); !ok && c[key] != nil {
+			return ErrMalformed
+		}
+	}
+	exp, ok := c.time("exp")
+	if !ok && !p.AllowNoExp {
+		return ErrMissingExp
+	}
+	if ok && !now.Before(exp.Add(p.Leeway)) {
+		return ErrExpired
+	}
+	if nbf, ok := c.time("nbf"); ok && now.Add(p.Leeway).Before(nbf) {
+		return ErrNotValidYet
+	}
+	if p.Issuer != "" && c.Issuer() != p.Issuer {
+		return ErrIssuer
+	}
+	if len(p.Audience) > 0 {
+		for _, aud := range c.Audience() {
+			for _, want := range p.Audience {
+				if aud == want {
+					return nil
+				}
+			}
+		}
+		return ErrAudience
+	}
+	return nil
+}
+
+// allow 算法是否允许，不支持 none 等其他算法
+func (p *Parser) allow(alg string) bool {
+	if alg != HS256 && alg != RS256 && alg != ES256 {
+		return false
+	}
+	if len(p.Algorithms) == 0 {
+		return true
+	}
+	for _, a := range p.Algorithms {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign 使用私钥或 HS256 密钥签发 JWT，key 为 []byte、*rsa.PrivateKey 或 *ecdsa.PrivateKey
+func Sign(alg, kid string, claims Claims, key any) (string, error) {
+	header, err := json.Marshal(Header{Alg: alg, Kid: kid, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(signed))
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		if alg != HS256 {
+			return "", ErrAlgorithm
+		}
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		if alg != RS256 {
+			return "", ErrAlgorithm
+		}
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, sum[:]); err != nil {
+			return "", err
+		}
+	case *ecdsa.PrivateKey:
+		if alg != ES256 || k.Curve != elliptic.P256() {
+			return "", ErrAlgorithm
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, k, sum[:])
+		if err != nil {
+			return "", err
+		}
+		// JWS 要求 r、s 各占 32 字节
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	default:
+		return "", fmt.Errorf("jwt: unsupported key type %T", key)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// verify 验证签名，密钥类型必须与算法对应，避免使用公钥作为 HS256 密钥伪造签名
+func verify(alg string, key any, signed, sig []byte) error {
+	sum := sha256.Sum256(signed)
+	switch alg {
+	case HS256:
+		k, ok := key.([]byte)
+		if !ok {
+			return ErrKeyNotFound
+		}
+		mac := hmac.New(sha256.New, k)
+		mac.Write(signed)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return ErrSignature
+		}
+	case RS256:
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrKeyNotFound
+		}
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig) != nil {
+			return ErrSignature
+		}
+	case ES256:
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok || k.Curve != elliptic.P256() {
+			return ErrKeyNotFound
+		}
+		if len(sig) != 64 {
+			return ErrSignature
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(k, sum[:], r, s) {
+			return ErrSignature
+		}
+	default:
+		return ErrAlgorithm
+	}
+	return nil
+}
+
+// decodeSegment 解码 base64url 编码的 JSON，数字解析为 json.Number 以保留精度
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return ErrMalformed
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err = d.Decode(v); err != nil {
+		return ErrMalformed
+	}
+	return nil
+}
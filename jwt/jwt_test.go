@@ -0,0 +1,137 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("test-secret")
+
+// testNow 固定的当前时间
+var testNow = time.Unix(1700000000, 0)
+
+// testClaims 在 testNow 时有效的 claims
+func testClaims() Claims {
+	return Claims{"sub": "u-1", "iss": "auth", "aud": []string{"api"}, "exp": testNow.Add(time.Hour).Unix()}
+}
+
+// testParser 使用 testNow 验证的 Parser
+func testParser() *Parser {
+	return &Parser{Now: func() time.Time { return testNow }}
+}
+
+// mustSign 签发 token，失败时结束测试
+func mustSign(t *testing.T, alg, kid string, claims Claims, key any) string {
+	t.Helper()
+	token, err := Sign(alg, kid, claims, key)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	return token
+}
+
+func TestSignAndParse(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := &StaticKeys{Default: []any{testSecret, &rsaKey.PublicKey, &ecKey.PublicKey}}
+	for alg, key := range map[string]any{HS256: testSecret, RS256: rsaKey, ES256: ecKey} {
+		token := mustSign(t, alg, "", testClaims(), key)
+		tk, err := testParser().Parse(token, keys)
+		if err != nil {
+			t.Fatalf("%s: Parse() error = %v", alg, err)
+		}
+		if tk.Header.Alg != alg || tk.Claims.Subject() != "u-1" || !tk.Claims.ExpiresAt().Equal(testNow.Add(time.Hour)) {
+			t.Fatalf("%s: token = %+v", alg, tk)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := &StaticKeys{Default: []any{testSecret}, Kids: map[string]any{"rsa": &rsaKey.PublicKey}}
+	token := mustSign(t, HS256, "", testClaims(), testSecret)
+	parts := strings.Split(token, ".")
+
+	for name, tt := range map[string]struct {
+		token string
+		want  error
+	}{
+		"malformed":     {"a.b", ErrMalformed},
+		"none":          {"eyJhbGciOiJub25lIn0." + parts[1] + ".", ErrAlgorithm},
+		"signature":     {parts[0] + "." + parts[1] + ".AAAA", ErrSignature},
+		"other secret":  {mustSign(t, HS256, "", testClaims(), []byte("other")), ErrSignature},
+		"rsa kid as hs": {mustSign(t, HS256, "rsa", testClaims(), testSecret), ErrKeyNotFound},
+	} {
+		if _, err := testParser().Parse(tt.token, keys); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Parse() error = %v, want %v", name, err, tt.want)
+		}
+	}
+
+	// kid 不在 Kids 中且没有 Default 时找不到密钥
+	if _, err := testParser().Parse(mustSign(t, HS256, "unknown", testClaims(), testSecret), &StaticKeys{Kids: keys.Kids}); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Parse() error = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	p := testParser()
+	p.Algorithms = []string{RS256}
+	if _, err := p.Parse(token, keys); !errors.Is(err, ErrAlgorithm) {
+		t.Errorf("Parse() error = %v, want %v", err, ErrAlgorithm)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	exp := testNow.Add(-10 * time.Second).Unix()
+	for name, tt := range map[string]struct {
+		parser Parser
+		claims Claims
+		want   error
+	}{
+		"valid":           {Parser{}, testClaims(), nil},
+		"missing exp":     {Parser{}, Claims{"sub": "u-1"}, ErrMissingExp},
+		"allow no exp":    {Parser{AllowNoExp: true}, Claims{"sub": "u-1"}, nil},
+		"invalid exp":     {Parser{AllowNoExp: true}, Claims{"exp": "tomorrow"}, ErrMalformed},
+		"expired":         {Parser{}, Claims{"exp": exp}, ErrExpired},
+		"expired leeway":  {Parser{Leeway: time.Minute}, Claims{"exp": exp}, nil},
+		"not valid yet":   {Parser{}, Claims{"exp": exp + 3600, "nbf": testNow.Add(time.Minute).Unix()}, ErrNotValidYet},
+		"issuer":          {Parser{Issuer: "other"}, testClaims(), ErrIssuer},
+		"audience":        {Parser{Audience: []string{"web", "api"}}, testClaims(), nil},
+		"wrong audience":  {Parser{Audience: []string{"web"}}, testClaims(), ErrAudience},
+		"string audience": {Parser{Audience: []string{"api"}}, Claims{"exp": exp + 3600, "aud": "api"}, nil},
+	} {
+		tt.parser.Now = func() time.Time { return testNow }
+		if err := tt.parser.Validate(tt.claims); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Validate() error = %v, want %v", name, err, tt.want)
+		}
+	}
+}
+
+func TestSignKeyMismatch(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Sign(RS256, "", testClaims(), testSecret); !errors.Is(err, ErrAlgorithm) {
+		t.Errorf("Sign(RS256, secret) error = %v", err)
+	}
+	if _, err = Sign(ES256, "", testClaims(), rsaKey); !errors.Is(err, ErrAlgorithm) {
+		t.Errorf("Sign(ES256, rsa) error = %v", err)
+	}
+	if _, err = Sign(HS256, "", testClaims(), "secret"); err == nil {
+		t.Error("Sign() should fail on string key")
+	}
+}
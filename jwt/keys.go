@@ -0,0 +1,102 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// KeySet 提供验证签名的密钥
+type KeySet interface {
+	// Keys 返回与 kid 及算法匹配的候选密钥，kid 为空时返回所有可用于该算法的密钥
+	Keys(kid, alg string) []any
+}
+
+// StaticKeys 固定的密钥，密钥为 HS256 使用的 []byte，或 RS256、ES256 使用的 *rsa.PublicKey、*ecdsa.PublicKey
+type StaticKeys struct {
+	Default []any          // 用于未指定 kid 或 kid 不在 Kids 中的 token
+	Kids    map[string]any // 按 kid 区分的密钥
+}
+
+// Keys 实现 KeySet 接口，未指定 kid 时返回所有可用于该算法的密钥
+func (s *StaticKeys) Keys(kid, alg string) []any {
+	if k, ok := s.Kids[kid]; ok && kid != "" {
+		if matchAlg(k, alg) {
+			return []any{k}
+		}
+		return nil
+	}
+	var list []any
+	for _, k := range s.Default {
+		if matchAlg(k, alg) {
+			list = append(list, k)
+		}
+	}
+	if kid == "" {
+		for _, k := range s.Kids {
+			if matchAlg(k, alg) {
+				list = append(list, k)
+			}
+		}
+	}
+	return list
+}
+
+// MultiKeySet 依次从多个 KeySet 中查找密钥
+type MultiKeySet []KeySet
+
+// Keys 实现 KeySet 接口
+func (m MultiKeySet) Keys(kid, alg string) []any {
+	var list []any
+	for _, s := range m {
+		if s != nil {
+			list = append(list, s.Keys(kid, alg)...)
+		}
+	}
+	return list
+}
+
+// ParsePublicKey 解析 PEM 格式的 RSA、ECDSA 公钥或证书
+func ParsePublicKey(data []byte) (any, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt: invalid PEM public key")
+	}
+	var key any
+	var err error
+	switch block.Type {
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jwt: parse public key fail: %w", err)
+	}
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("jwt: unsupported public key type %T", key)
+}
+
+// matchAlg 密钥类型是否可用于该算法
+func matchAlg(key any, alg string) bool {
+	switch key.(type) {
+	case []byte:
+		return alg == HS256
+	case *rsa.PublicKey:
+		return alg == RS256
+	case *ecdsa.PublicKey:
+		return alg == ES256
+	}
+	return false
+}
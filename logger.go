@@ -4,8 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/metlive/gohera/rotatelogs"
@@ -16,54 +16,70 @@ import (
 type Trace struct {
 	TraceId string `json:"trace_id"`
 	SpanId  string `json:"span_id"`
-	UserId  int    `json:"user_id"`
+	UserId  string `json:"user_id"`
 	Method  string `json:"method"`
 	Path    string `json:"path"`
 	Status  int    `json:"status"`
 	Headers map[string]any
-}
-
-// 定义统一的日志写入方式
-var logger *zap.Logger
 
-type loggerConfig struct {
-	FilePath   string `json:"file_path"`
-	MaxSize    int    `json:"max_size"`
-	MaxBackups int    `json:"max_backups"`
-	Compress   bool   `json:"compress"`
-	Mode       string `json:"mode"` // 环境
+	HexSpanId   string            `json:"hex_span_id"`   // W3C/B3 格式的 16 位十六进制 Span ID
+	HexParentId string            `json:"hex_parent_id"` // 上游传入的 W3C/B3 格式的 Span ID
+	Sampled     *bool             `json:"sampled"`       // 上游的采样决定，为 nil 时未指定
+	TraceState  string            `json:"trace_state"`   // W3C tracestate，原样传递给下游
+	Baggage     map[string]string `json:"baggage"`       // W3C baggage，传递给下游
 }
 
-// initLoggerPool 初始化日志连接池
-// 根据配置初始化不同级别的日志输出（Debug, Info, Warn, Error）
-func initLoggerPool(config loggerConfig) {
-	// 调试级别
-	debugPriority := zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
-		return lev == zap.DebugLevel
-	})
-	// 日志级别
-	infoPriority := zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
-		return lev == zap.InfoLevel
-	})
-	// 警告级别
-	warnPriority := zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
-		return lev == zap.WarnLevel
-	})
-	// 错误级别
-	errorPriority := zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
-		return lev >= zap.ErrorLevel
-	})
+// 定义统一的日志写入方式，未初始化前丢弃所有日志
+var logger = zap.NewNop()
 
-	// 文件输出 Core (保持 JSON 格式)
-	cores := []zapcore.Core{
-		getEncoderCore(fmt.Sprintf("./%s/server_debug.log", config.FilePath), debugPriority, config),
-		getEncoderCore(fmt.Sprintf("./%s/server_info.log", config.FilePath), infoPriority, config),
-		getEncoderCore(fmt.Sprintf("./%s/server_warn.log", config.FilePath), warnPriority, config),
-		getEncoderCore(fmt.Sprintf("./%s/server_error.log", config.FilePath), errorPriority, config),
+// initLoggerPool 初始化日志连接池
+// 根据配置初始化不同级别的日志输出（Debug, Info, Warn, Error），split 为 false 时所有级别输出到同一个文件
+func initLoggerPool(config loggerConfig) error {
+	var cores []zapcore.Core
+	if config.Split {
+		// 调试级别
+		debugPriority := zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
+			return lev == zap.DebugLevel
+		})
+		// 日志级别
+		infoPriority := zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
+			return lev == zap.InfoLevel
+		})
+		// 警告级别
+		warnPriority := zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
+			return lev == zap.WarnLevel
+		})
+		// 错误级别
+		errorPriority := zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
+			return lev >= zap.ErrorLevel
+		})
+
+		files := []struct {
+			name  string
+			level zapcore.LevelEnabler
+		}{
+			{"debug", debugPriority},
+			{"info", infoPriority},
+			{"warn", warnPriority},
+			{"error", errorPriority},
+		}
+		for _, f := range files {
+			core, err := getEncoderCore(f.name, filepath.Join(config.FilePath, "server_"+f.name+".log"), f.level, config)
+			if err != nil {
+				return err
+			}
+			cores = append(cores, core)
+		}
+	} else {
+		core, err := getEncoderCore("server", filepath.Join(config.FilePath, "server.log"), zap.DebugLevel, config)
+		if err != nil {
+			return err
+		}
+		cores = append(cores, core)
 	}
 
-	// 新增：非正式环境添加控制台输出 (无格式纯文本)
-	if GetBool("log.stdout") {
+	// 新增：非正式环境添加控制台输出 (无格式纯文本)，已输出 JSON 到标准输出时不再重复输出
+	if config.Stdout && config.Output != LogOutputConsole && config.Output != LogOutputBoth {
 		// 使用 zap.DebugLevel 允许输出 Debug 及以上所有级别日志
 		cores = append(cores, getConsoleCore(zap.DebugLevel))
 	}
@@ -72,8 +88,20 @@ func initLoggerPool(config loggerConfig) {
 		zap.String("x_type", "go"),
 		zap.String("x_project", GetString("http.service")),
 	)
-	core := zapcore.NewTee(cores...)
-	logger = zap.New(core, filed).WithOptions(zap.AddCallerSkip(1))
+	// 告警在采样之前，被采样丢弃的日志仍会计入告警
+	core := &levelFilterCore{Core: &alertCore{Core: &samplingCore{Core: zapcore.NewTee(cores...)}}}
+	logger = zap.New(core, filed).WithOptions(zap.AddCallerSkip(2))
+
+	// 请求日志输出到独立的文件，不受日志级别控制
+	accessLogger = zap.NewNop()
+	if config.Access.Enable {
+		core, err := getEncoderCore("access", filepath.Join(config.FilePath, config.Access.File), zap.DebugLevel, config)
+		if err != nil {
+			return err
+		}
+		accessLogger = zap.New(core, filed)
+	}
+	return nil
 }
 
 // getConsoleCore 获取控制台输出 Core (极简格式)
@@ -84,7 +112,7 @@ func getConsoleCore(level zapcore.LevelEnabler) zapcore.Core {
 	}), zapcore.AddSync(os.Stdout), level)
 
 	// 返回一个 cleanConsoleCore 实例，用于精简控制台日志输出（忽略添加的字段，只输出 msg 字段的内容）
-	return &cleanConsoleCore{Core: core}
+	return &maskCore{Core: &cleanConsoleCore{Core: core}}
 }
 
 type cleanConsoleCore struct {
@@ -111,36 +139,56 @@ func (c *cleanConsoleCore) Write(ent zapcore.Entry, fields []zapcore.Field) erro
 }
 
 // getEncoderCore 获取文件输出 Core 配置
-// 负责配置日志文件的切割、格式（JSON）及输出级别
-func getEncoderCore(fileName string, level zapcore.LevelEnabler, config loggerConfig) (core zapcore.Core) {
-	// 每小时一个文件
-	logf, _ := rotatelogs.New(fileName+"_%Y-%m-%d",
-		rotatelogs.WithLinkName(fileName),
-		rotatelogs.WithMaxAge(7*24*time.Hour),
-		rotatelogs.WithRotationTime(24*time.Hour),
-	)
+// 负责配置日志文件的切割、格式（JSON）及输出级别，name 为 [log.async] 中 cores 使用的名称
+// output 为 console 或 both 时同样格式的 JSON 输出到标准输出
+func getEncoderCore(name, fileName string, level zapcore.LevelEnabler, config loggerConfig) (zapcore.Core, error) {
+	var cores []zapcore.Core
+	if config.Output != LogOutputConsole {
+		logf, err := rotatelogs.New(fileName+"_"+config.FilePattern, config.rotateOptions(fileName)...)
+		if err != nil {
+			return nil, fmt.Errorf("log file %s: %w", fileName, err)
+		}
 
-	// 修改处：不再包含 os.Stdout，只写入文件
-	writer := zapcore.AddSync(logf)
-
-	return zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{
-		MessageKey:    "x_message",
-		LevelKey:      "x_level",
-		StacktraceKey: "x_trace",
-		TimeKey:       "x_time",
-		NameKey:       "x_logger",
-		CallerKey:     "x_caller",
-		LineEnding:    zapcore.DefaultLineEnding,
-		EncodeLevel:   zapcore.LowercaseLevelEncoder,
-		EncodeTime: func(t time.Time, encoder zapcore.PrimitiveArrayEncoder) {
-			encoder.AppendString(t.Format("2006-01-02 15:04:05"))
-		},
-		EncodeDuration: zapcore.SecondsDurationEncoder,
-		EncodeCaller:   zapcore.FullCallerEncoder,
-		EncodeName:     zapcore.FullNameEncoder,
-	}), writer, level)
+		// 修改处：不再包含 os.Stdout，只写入文件
+		writer := zapcore.AddSync(logf)
+		if config.Async.enabled(name) {
+			writer = newAsyncWriter(name, logf, config.Async)
+		}
+		cores = append(cores, &maskCore{Core: zapcore.NewCore(zapcore.NewJSONEncoder(config.encoderConfig()), writer, level)})
+	}
+	if config.Output == LogOutputConsole || config.Output == LogOutputBoth {
+		cores = append(cores, getConsoleJSONCores(level, config)...)
+	}
+	return zapcore.NewTee(cores...), nil
+}
+
+// getConsoleJSONCores 获取标准输出的 JSON Core
+// 设置 stderr_level 时该级别及以上的日志输出到标准错误，多个 Core 共用加锁的输出，保证日志不会交错
+func getConsoleJSONCores(level zapcore.LevelEnabler, config loggerConfig) []zapcore.Core {
+	if config.StderrLevel == "" {
+		return []zapcore.Core{
+			&maskCore{Core: zapcore.NewCore(zapcore.NewJSONEncoder(config.encoderConfig()), consoleStdout, level)},
+		}
+	}
+	var threshold zapcore.Level
+	_ = threshold.Set(config.StderrLevel)
+	stdoutLevel := zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
+		return lev < threshold && level.Enabled(lev)
+	})
+	stderrLevel := zap.LevelEnablerFunc(func(lev zapcore.Level) bool {
+		return lev >= threshold && level.Enabled(lev)
+	})
+	return []zapcore.Core{
+		&maskCore{Core: zapcore.NewCore(zapcore.NewJSONEncoder(config.encoderConfig()), consoleStdout, stdoutLevel)},
+		&maskCore{Core: zapcore.NewCore(zapcore.NewJSONEncoder(config.encoderConfig()), consoleStderr, stderrLevel)},
+	}
 }
 
+var (
+	consoleStdout = zapcore.Lock(os.Stdout)
+	consoleStderr = zapcore.Lock(os.Stderr)
+)
+
 // GetTraceContext 从 Context 中获取 Trace 信息
 // 如果 Context 中不存在 Trace 信息，则返回空的 Trace 对象
 func GetTraceContext(ctx context.Context) *Trace {
@@ -164,15 +212,29 @@ func getContextFields(ctx context.Context) []zap.Field {
 	}
 	zapFiled := make([]zap.Field, 0, 6)
 	traceInfo := GetTraceContext(ctx)
-	zapFiled = append(zapFiled, zap.String("x_trace_id", Ternary[string](traceInfo.TraceId == "", strings.ReplaceAll(uuid.NewString(), "-", ""), traceInfo.TraceId)))
+	zapFiled = append(zapFiled, traceIdField(traceInfo.TraceId))
 	zapFiled = append(zapFiled, zap.String("x_span_id", Ternary[string](traceInfo.SpanId == "", SpanIdDefault, traceInfo.SpanId)))
-	zapFiled = append(zapFiled, zap.Int("x_user_id", Ternary[int](traceInfo.UserId == 0, 0, traceInfo.UserId)))
+	zapFiled = append(zapFiled, zap.String("x_user_id", Ternary[string](traceInfo.UserId == "", CurrentUserId(ctx), traceInfo.UserId)))
 	zapFiled = append(zapFiled, zap.String("x_path", traceInfo.Path))
 	zapFiled = append(zapFiled, zap.Int("x_status", traceInfo.Status))
 	zapFiled = append(zapFiled, zap.Any("x_header", traceInfo.Headers))
 	return zapFiled
 }
 
+// generatedTraceId 标记 x_trace_id 为没有 Trace 信息时生成的随机值，采样时视为没有 trace_id
+type generatedTraceId struct{}
+
+// traceIdField x_trace_id 字段，traceId 为空时生成随机值
+func traceIdField(traceId string) zap.Field {
+	if traceId != "" {
+		return zap.String("x_trace_id", traceId)
+	}
+	f := zap.String("x_trace_id", strings.ReplaceAll(uuid.NewString(), "-", ""))
+	// 字符串字段不会输出 Interface，仅用于区分生成的值
+	f.Interface = generatedTraceId{}
+	return f
+}
+
 // StartSpan 处理日志格式化并从 Context 中提取跟踪信息
 func StartSpan(ctx context.Context, format string, args ...any) (string, []zap.Field) {
 	// 判断是否有context
@@ -183,79 +245,275 @@ func StartSpan(ctx context.Context, format string, args ...any) (string, []zap.F
 	return format, getContextFields(ctx)
 }
 
+// Debug 输出 Debug 级别日志
+func Debug(ctx context.Context, args ...any) {
+	rootLogger.log(ctx, zap.DebugLevel, "%v", args, nil)
+}
+
+// Debugtf 输出带格式化的 Debug 级别日志
+func Debugtf(ctx context.Context, template string, args ...any) {
+	rootLogger.log(ctx, zap.DebugLevel, template, args, nil)
+}
+
 // Info 输出 Info 级别日志
 func Info(ctx context.Context, args ...any) {
-	str, filed := StartSpan(ctx, "%v", args...)
-	logger.Info(str, filed...)
+	rootLogger.log(ctx, zap.InfoLevel, "%v", args, nil)
 }
 
 // Infotf 输出带格式化的 Info 级别日志
 func Infotf(ctx context.Context, template string, args ...any) {
-	str, filed := StartSpan(ctx, template, args...)
-	logger.Info(str, filed...)
+	rootLogger.log(ctx, zap.InfoLevel, template, args, nil)
 }
 
 // Warn 输出 Warn 级别日志
 func Warn(ctx context.Context, args ...any) {
-	str, filed := StartSpan(ctx, "%v", args...)
-	logger.Warn(str, filed...)
+	rootLogger.log(ctx, zap.WarnLevel, "%v", args, nil)
 }
 
 // Warntf 输出带格式化的 Warn 级别日志
 func Warntf(ctx context.Context, template string, args ...any) {
-	str, filed := StartSpan(ctx, template, args...)
-	logger.Warn(str, filed...)
+	rootLogger.log(ctx, zap.WarnLevel, template, args, nil)
 }
 
 // Error 输出 Error 级别日志
 func Error(ctx context.Context, args ...any) {
-	str, filed := StartSpan(ctx, "%v", args...)
-	logger.Error(str, filed...)
+	rootLogger.log(ctx, zap.ErrorLevel, "%v", args, nil)
 }
 
 // Errortf 输出带格式化的 Error 级别日志
 func Errortf(ctx context.Context, template string, args ...any) {
-	str, filed := StartSpan(ctx, template, args...)
-	logger.Error(str, filed...)
+	rootLogger.log(ctx, zap.ErrorLevel, template, args, nil)
+}
+
+// Fatal 输出 Fatal 级别日志，写入后调用 os.Exit(1) 退出，不会执行关闭钩子
+func Fatal(ctx context.Context, args ...any) {
+	rootLogger.log(ctx, zap.FatalLevel, "%v", args, nil)
+}
+
+// Fataltf 输出带格式化的 Fatal 级别日志，写入后调用 os.Exit(1) 退出，不会执行关闭钩子
+func Fataltf(ctx context.Context, template string, args ...any) {
+	rootLogger.log(ctx, zap.FatalLevel, template, args, nil)
+}
+
+// DebugFields 输出带结构化字段的 Debug 级别日志
+// 字段与 Trace 信息一起作为独立的 JSON 字段输出，便于日志平台检索，如 DebugFields(ctx, "paid", zap.String("order_id", id))
+func DebugFields(ctx context.Context, msg string, fields ...zap.Field) {
+	rootLogger.log(ctx, zap.DebugLevel, msg, nil, fields)
+}
+
+// InfoFields 输出带结构化字段的 Info 级别日志
+func InfoFields(ctx context.Context, msg string, fields ...zap.Field) {
+	rootLogger.log(ctx, zap.InfoLevel, msg, nil, fields)
+}
+
+// WarnFields 输出带结构化字段的 Warn 级别日志
+func WarnFields(ctx context.Context, msg string, fields ...zap.Field) {
+	rootLogger.log(ctx, zap.WarnLevel, msg, nil, fields)
+}
+
+// ErrorFields 输出带结构化字段的 Error 级别日志
+func ErrorFields(ctx context.Context, msg string, fields ...zap.Field) {
+	rootLogger.log(ctx, zap.ErrorLevel, msg, nil, fields)
+}
+
+// FatalFields 输出带结构化字段的 Fatal 级别日志，写入后调用 os.Exit(1) 退出
+func FatalFields(ctx context.Context, msg string, fields ...zap.Field) {
+	rootLogger.log(ctx, zap.FatalLevel, msg, nil, fields)
+}
+
+// rootLogger 包级别日志函数使用的日志记录器
+var rootLogger = &ModuleLogger{}
+
+// ModuleLogger 模块日志记录器
+// 日志中的 x_logger 字段为模块名，日志级别可以通过 [log.modules] 配置或运维接口单独调整
+type ModuleLogger struct {
+	name string
+}
+
+// Named 获取模块日志记录器，如 gohera.Named("payment")
+func Named(name string) *ModuleLogger {
+	return &ModuleLogger{name: name}
+}
+
+// log 输出日志，调用层级固定为 调用方 -> 日志函数 -> log，保证 x_caller 指向调用方
+// fields 追加在 Trace 信息之后；通过 SetSlogOutput 设置输出目标后转发到 slog.Handler
+func (m *ModuleLogger) log(ctx context.Context, lvl zapcore.Level, template string, args []any, fields []zap.Field) {
+	if h := slogOutput.Load(); h != nil {
+		m.logSlog(*h, ctx, lvl, template, args, fields)
+		return
+	}
+	l := logger
+	if m.name != "" {
+		l = l.Named(m.name)
+	}
+	if ce := l.Check(lvl, ""); ce != nil {
+		msg, ctxFields := StartSpan(ctx, template, args...)
+		ce.Message = msg
+		ce.Write(append(ctxFields, fields...)...)
+	}
+}
+
+// Debug 输出 Debug 级别日志
+func (m *ModuleLogger) Debug(ctx context.Context, args ...any) {
+	m.log(ctx, zap.DebugLevel, "%v", args, nil)
+}
+
+// Debugtf 输出带格式化的 Debug 级别日志
+func (m *ModuleLogger) Debugtf(ctx context.Context, template string, args ...any) {
+	m.log(ctx, zap.DebugLevel, template, args, nil)
+}
+
+// Info 输出 Info 级别日志
+func (m *ModuleLogger) Info(ctx context.Context, args ...any) {
+	m.log(ctx, zap.InfoLevel, "%v", args, nil)
+}
+
+// Infotf 输出带格式化的 Info 级别日志
+func (m *ModuleLogger) Infotf(ctx context.Context, template string, args ...any) {
+	m.log(ctx, zap.InfoLevel, template, args, nil)
+}
+
+// Warn 输出 Warn 级别日志
+func (m *ModuleLogger) Warn(ctx context.Context, args ...any) {
+	m.log(ctx, zap.WarnLevel, "%v", args, nil)
+}
+
+// Warntf 输出带格式化的 Warn 级别日志
+func (m *ModuleLogger) Warntf(ctx context.Context, template string, args ...any) {
+	m.log(ctx, zap.WarnLevel, template, args, nil)
+}
+
+// Error 输出 Error 级别日志
+func (m *ModuleLogger) Error(ctx context.Context, args ...any) {
+	m.log(ctx, zap.ErrorLevel, "%v", args, nil)
+}
+
+// Errortf 输出带格式化的 Error 级别日志
+func (m *ModuleLogger) Errortf(ctx context.Context, template string, args ...any) {
+	m.log(ctx, zap.ErrorLevel, template, args, nil)
+}
+
+// Fatal 输出 Fatal 级别日志，写入后调用 os.Exit(1) 退出
+func (m *ModuleLogger) Fatal(ctx context.Context, args ...any) {
+	m.log(ctx, zap.FatalLevel, "%v", args, nil)
+}
+
+// Fataltf 输出带格式化的 Fatal 级别日志，写入后调用 os.Exit(1) 退出
+func (m *ModuleLogger) Fataltf(ctx context.Context, template string, args ...any) {
+	m.log(ctx, zap.FatalLevel, template, args, nil)
+}
+
+// DebugFields 输出带结构化字段的 Debug 级别日志
+func (m *ModuleLogger) DebugFields(ctx context.Context, msg string, fields ...zap.Field) {
+	m.log(ctx, zap.DebugLevel, msg, nil, fields)
+}
+
+// InfoFields 输出带结构化字段的 Info 级别日志
+func (m *ModuleLogger) InfoFields(ctx context.Context, msg string, fields ...zap.Field) {
+	m.log(ctx, zap.InfoLevel, msg, nil, fields)
+}
+
+// WarnFields 输出带结构化字段的 Warn 级别日志
+func (m *ModuleLogger) WarnFields(ctx context.Context, msg string, fields ...zap.Field) {
+	m.log(ctx, zap.WarnLevel, msg, nil, fields)
+}
+
+// ErrorFields 输出带结构化字段的 Error 级别日志
+func (m *ModuleLogger) ErrorFields(ctx context.Context, msg string, fields ...zap.Field) {
+	m.log(ctx, zap.ErrorLevel, msg, nil, fields)
+}
+
+// FatalFields 输出带结构化字段的 Fatal 级别日志，写入后调用 os.Exit(1) 退出
+func (m *ModuleLogger) FatalFields(ctx context.Context, msg string, fields ...zap.Field) {
+	m.log(ctx, zap.FatalLevel, msg, nil, fields)
+}
+
+// Ctx 创建一个绑定了 Context 的模块日志记录器
+func (m *ModuleLogger) Ctx(ctx context.Context) *ContextLogger {
+	return &ContextLogger{ctx: ctx, module: m}
 }
 
 // ContextLogger 绑定了 Context 的日志记录器
 type ContextLogger struct {
-	ctx context.Context
+	ctx    context.Context
+	module *ModuleLogger
+	fields []zap.Field
 }
 
 // Ctx 创建一个绑定了 Context 的日志记录器
 // 后续调用 Info/Warn/Error 等方法时无需再次传入 Context
 func Ctx(ctx context.Context) *ContextLogger {
-	return &ContextLogger{ctx: ctx}
+	return &ContextLogger{ctx: ctx, module: rootLogger}
+}
+
+// With 返回附加了结构化字段的日志记录器，后续输出的每条日志都会带上这些字段
+// 参数为交替的 key/value，如 With("order_id", id, "amount", amount)，也可以直接传入 zap.Field
+func (l *ContextLogger) With(keysAndValues ...any) *ContextLogger {
+	fields := make([]zap.Field, 0, len(l.fields)+len(keysAndValues)/2)
+	fields = append(fields, l.fields...)
+	for i := 0; i < len(keysAndValues); i++ {
+		if f, ok := keysAndValues[i].(zap.Field); ok {
+			fields = append(fields, f)
+			continue
+		}
+		key := fmt.Sprint(keysAndValues[i])
+		if i == len(keysAndValues)-1 {
+			// 缺少 value 的 key 原样保留，便于发现调用错误
+			fields = append(fields, zap.Any("x_ignored", key))
+			break
+		}
+		i++
+		fields = append(fields, zap.Any(key, keysAndValues[i]))
+	}
+	return &ContextLogger{ctx: l.ctx, module: l.module, fields: fields}
+}
+
+// Debug 输出 Debug 级别日志 (使用绑定的 Context)
+func (l *ContextLogger) Debug(args ...any) {
+	l.module.log(l.ctx, zap.DebugLevel, "%v", args, l.fields)
+}
+
+// Debugtf 输出带格式化的 Debug 级别日志 (使用绑定的 Context)
+func (l *ContextLogger) Debugtf(template string, args ...any) {
+	l.module.log(l.ctx, zap.DebugLevel, template, args, l.fields)
 }
 
 // Info 输出 Info 级别日志 (使用绑定的 Context)
 func (l *ContextLogger) Info(args ...any) {
-	Info(l.ctx, args...)
+	l.module.log(l.ctx, zap.InfoLevel, "%v", args, l.fields)
 }
 
 // Infotf 输出带格式化的 Info 级别日志 (使用绑定的 Context)
 func (l *ContextLogger) Infotf(template string, args ...any) {
-	Infotf(l.ctx, template, args...)
+	l.module.log(l.ctx, zap.InfoLevel, template, args, l.fields)
 }
 
 // Warn 输出 Warn 级别日志 (使用绑定的 Context)
 func (l *ContextLogger) Warn(args ...any) {
-	Warn(l.ctx, args...)
+	l.module.log(l.ctx, zap.WarnLevel, "%v", args, l.fields)
 }
 
 // Warntf 输出带格式化的 Warn 级别日志 (使用绑定的 Context)
 func (l *ContextLogger) Warntf(template string, args ...any) {
-	Warntf(l.ctx, template, args...)
+	l.module.log(l.ctx, zap.WarnLevel, template, args, l.fields)
 }
 
 // Error 输出 Error 级别日志 (使用绑定的 Context)
 func (l *ContextLogger) Error(args ...any) {
-	Error(l.ctx, args...)
+	l.module.log(l.ctx, zap.ErrorLevel, "%v", args, l.fields)
 }
 
 // Errortf 输出带格式化的 Error 级别日志 (使用绑定的 Context)
 func (l *ContextLogger) Errortf(template string, args ...any) {
-	Errortf(l.ctx, template, args...)
+	l.module.log(l.ctx, zap.ErrorLevel, template, args, l.fields)
+}
+
+// Fatal 输出 Fatal 级别日志并退出 (使用绑定的 Context)
+func (l *ContextLogger) Fatal(args ...any) {
+	l.module.log(l.ctx, zap.FatalLevel, "%v", args, l.fields)
+}
+
+// Fataltf 输出带格式化的 Fatal 级别日志并退出 (使用绑定的 Context)
+func (l *ContextLogger) Fataltf(template string, args ...any) {
+	l.module.log(l.ctx, zap.FatalLevel, template, args, l.fields)
 }
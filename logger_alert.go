@@ -0,0 +1,438 @@
+package gohera
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// alertConfig [log.alert] 配置
+type alertConfig struct {
+	Enable   bool            `mapstructure:"enable"`
+	Level    string          `mapstructure:"level" binding:"omitempty,oneof=debug info warn error dpanic panic fatal"` // 触发告警的最低级别
+	Window   time.Duration   `mapstructure:"window" binding:"gte=0"`                                                   // 聚合窗口，窗口内相同的日志合并为一条告警
+	Throttle time.Duration   `mapstructure:"throttle" binding:"gte=0"`                                                 // 相同日志两次告警的最小间隔，期间的日志计入下一条告警
+	Webhooks []webhookConfig `mapstructure:"webhooks" binding:"dive"`
+}
+
+// webhookConfig 通用 Webhook 告警配置
+type webhookConfig struct {
+	Name    string            `mapstructure:"name"`
+	URL     string            `mapstructure:"url" binding:"required,url"`
+	Method  string            `mapstructure:"method" binding:"omitempty,oneof=POST PUT"`
+	Headers map[string]string `mapstructure:"headers"`
+	Body    string            `mapstructure:"body"` // 请求内容的 text/template 模板，为空时发送 Alert 的 JSON
+	Timeout time.Duration     `mapstructure:"timeout" binding:"gte=0"`
+}
+
+// defaultAlertConfig 默认告警配置
+func defaultAlertConfig() alertConfig {
+	return alertConfig{
+		Level:    "error",
+		Window:   time.Minute,
+		Throttle: 10 * time.Minute,
+	}
+}
+
+// alertLoggerName 告警发送失败时使用的模块名，该模块的日志不会触发告警
+const alertLoggerName = "alert"
+
+// Alert 告警内容
+type Alert struct {
+	Level     string         `json:"level"`
+	Message   string         `json:"message"`
+	Logger    string         `json:"logger,omitempty"`
+	Caller    string         `json:"caller,omitempty"`
+	Panic     bool           `json:"panic"`
+	Count     int            `json:"count"` // 聚合的日志条数
+	FirstTime time.Time      `json:"first_time"`
+	LastTime  time.Time      `json:"last_time"`
+	TraceIds  []string       `json:"trace_ids,omitempty"` // 最多保留 5 个
+	Fields    map[string]any `json:"fields,omitempty"`    // 第一条日志脱敏后的字段
+	App       string         `json:"app"`
+	Env       string         `json:"env"`
+	Namespace string         `json:"namespace,omitempty"`
+	Pod       string         `json:"pod,omitempty"`
+	Version   string         `json:"version,omitempty"`
+}
+
+// AlertSink 告警发送目标
+type AlertSink interface {
+	// Name 发送目标名称，用于记录发送失败的日志
+	Name() string
+	// Send 发送告警，ctx 超时后应当放弃发送
+	Send(ctx context.Context, alert *Alert) error
+}
+
+var (
+	alertSinks   []AlertSink
+	alertSinksMu sync.Mutex
+)
+
+// AddAlertSink 添加告警发送目标，与 [log.alert] 中配置的 Webhook 一起使用，需要在 New/InitApp 之前调用
+func AddAlertSink(sink AlertSink) {
+	alertSinksMu.Lock()
+	defer alertSinksMu.Unlock()
+	alertSinks = append(alertSinks, sink)
+}
+
+// alertSendTimeout 单次发送告警的超时时间
+const alertSendTimeout = 5 * time.Second
+
+// alerter 告警的聚合及限流
+type alerter struct {
+	cfg   alertConfig
+	level zapcore.Level
+	sinks []AlertSink
+
+	mu       sync.Mutex
+	groups   map[string]*Alert
+	lastSent map[string]time.Time
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+var logAlerter atomic.Pointer[alerter]
+
+// newAlerter 根据配置创建告警器，未开启或没有发送目标时返回 nil
+func newAlerter(cfg alertConfig) (*alerter, error) {
+	if !cfg.Enable {
+		return nil, nil
+	}
+	def := defaultAlertConfig()
+	if cfg.Level == "" {
+		cfg.Level = def.Level
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	a := &alerter{
+		cfg:      cfg,
+		groups:   make(map[string]*Alert),
+		lastSent: make(map[string]time.Time),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := a.level.Set(cfg.Level); err != nil {
+		return nil, fmt.Errorf("log.alert.level: %w", err)
+	}
+	for i, wc := range cfg.Webhooks {
+		sink, err := newWebhookSink(wc)
+		if err != nil {
+			return nil, fmt.Errorf("log.alert.webhooks[%d]: %w", i, err)
+		}
+		a.sinks = append(a.sinks, sink)
+	}
+	alertSinksMu.Lock()
+	a.sinks = append(a.sinks, alertSinks...)
+	alertSinksMu.Unlock()
+	if len(a.sinks) == 0 {
+		return nil, nil
+	}
+	go a.run()
+	return a, nil
+}
+
+// applyAlertConfig 应用告警配置，替换时发送原告警器中尚未发送的告警
+func applyAlertConfig(cfg alertConfig) error {
+	a, err := newAlerter(cfg)
+	if err != nil {
+		return err
+	}
+	if old := logAlerter.Swap(a); old != nil {
+		old.close()
+	}
+	return nil
+}
+
+// watchAlertConfig 配置热更新时重新应用告警配置
+func watchAlertConfig() {
+	OnChange("log.alert", func(_, _ any) {
+		cfg, err := loadLoggerConfig()
+		if err == nil {
+			err = applyAlertConfig(cfg.Alert)
+		}
+		if err != nil {
+			Errortf(context.Background(), "log alert config fail: %v", err)
+		}
+	})
+}
+
+// add 记录一条日志，Fatal 等高于 Error 的日志之后进程可能退出，因此立即发送
+func (a *alerter) add(ent zapcore.Entry, fields []zapcore.Field) {
+	m := masker.Load()
+	key := ent.LoggerName + "|" + ent.Level.String() + "|" + ent.Message
+	panicErr := fieldString(fields, "x_panic")
+	if panicErr != "" {
+		// panic 日志的内容包含请求及堆栈，按错误信息聚合
+		key = "panic|" + panicErr
+	}
+	traceId := fieldString(fields, "x_trace_id")
+
+	if ent.Level > zapcore.ErrorLevel {
+		alert := a.newAlert(m, ent, fields, panicErr)
+		if traceId != "" {
+			alert.TraceIds = []string{traceId}
+		}
+		a.send([]*Alert{alert})
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	alert, ok := a.groups[key]
+	if !ok {
+		alert = a.newAlert(m, ent, fields, panicErr)
+		a.groups[key] = alert
+	}
+	alert.Count++
+	alert.LastTime = ent.Time
+	if traceId != "" && len(alert.TraceIds) < dedupSampleTraces {
+		alert.TraceIds = append(alert.TraceIds, traceId)
+	}
+}
+
+// newAlert 根据日志创建告警，内容及字段脱敏后发送
+func (a *alerter) newAlert(m *Masker, ent zapcore.Entry, fields []zapcore.Field, panicErr string) *Alert {
+	alert := &Alert{
+		Level:     ent.Level.String(),
+		Message:   ent.Message,
+		Logger:    ent.LoggerName,
+		Panic:     panicErr != "",
+		FirstTime: ent.Time,
+		LastTime:  ent.Time,
+		App:       GetAppName(),
+		Env:       GetEnv(),
+		Namespace: GetAppNamespace(),
+		Pod:       GetAppPodName(),
+		Version:   GetAppVersion(),
+	}
+	if alert.App == "" {
+		alert.App = GetString("http.service")
+	}
+	if panicErr != "" {
+		alert.Message = "panic: " + panicErr
+	}
+	if ent.Caller.Defined {
+		alert.Caller = ent.Caller.TrimmedPath()
+	}
+	if !m.disable {
+		alert.Message = m.maskString(alert.Message)
+		fields = m.maskFields(fields)
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	if len(enc.Fields) > 0 {
+		alert.Fields = enc.Fields
+	}
+	return alert
+}
+
+// run 定时发送聚合窗口已结束的告警
+func (a *alerter) run() {
+	interval := a.cfg.Window / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer close(a.done)
+	for {
+		select {
+		case <-a.stop:
+			a.flush(time.Time{})
+			return
+		case now := <-ticker.C:
+			a.flush(now)
+		}
+	}
+}
+
+// close 停止告警器，等待尚未发送的告警发送完成
+func (a *alerter) close() {
+	if a != nil {
+		close(a.stop)
+		<-a.done
+	}
+}
+
+// flush 发送聚合窗口已结束且不在限流期内的告警，now 为零值时发送全部
+func (a *alerter) flush(now time.Time) {
+	a.mu.Lock()
+	var due []*Alert
+	for key, alert := range a.groups {
+		if !now.IsZero() {
+			if now.Sub(alert.FirstTime) < a.cfg.Window || now.Sub(a.lastSent[key]) < a.cfg.Throttle {
+				continue
+			}
+		}
+		delete(a.groups, key)
+		a.lastSent[key] = now
+		due = append(due, alert)
+	}
+	for key, t := range a.lastSent {
+		if now.Sub(t) >= a.cfg.Throttle {
+			delete(a.lastSent, key)
+		}
+	}
+	a.mu.Unlock()
+
+	a.send(due)
+}
+
+// send 发送告警到所有目标，发送失败时以 Warn 级别记录
+func (a *alerter) send(alerts []*Alert) {
+	for _, alert := range alerts {
+		for _, sink := range a.sinks {
+			ctx, cancel := context.WithTimeout(context.Background(), alertSendTimeout)
+			if err := sink.Send(ctx, alert); err != nil {
+				Named(alertLoggerName).Warntf(ctx, "send alert to %s fail: %v", sink.Name(), err)
+			}
+			cancel()
+		}
+	}
+}
+
+// alertCore 将达到告警级别的日志交给告警器，不影响日志的写入
+type alertCore struct {
+	zapcore.Core
+	fields []zapcore.Field
+}
+
+// With 实现 zapcore.Core 接口，添加字段
+func (c *alertCore) With(fields []zapcore.Field) zapcore.Core {
+	all := make([]zapcore.Field, len(c.fields), len(c.fields)+len(fields))
+	copy(all, c.fields)
+	return &alertCore{Core: c.Core.With(fields), fields: append(all, fields...)}
+}
+
+// Check 实现 zapcore.Core 接口
+func (c *alertCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ce = c.Core.Check(ent, ce)
+	if a := logAlerter.Load(); a != nil && ent.Level >= a.level && ent.LoggerName != alertLoggerName {
+		ce = ce.AddCore(ent, alertHook{fields: c.fields})
+	}
+	return ce
+}
+
+// alertHook 只记录告警，不写入日志
+type alertHook struct {
+	fields []zapcore.Field
+}
+
+// Enabled 实现 zapcore.Core 接口，级别已在 alertCore 中判断
+func (h alertHook) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With 实现 zapcore.Core 接口，字段已在 alertCore 中记录
+func (h alertHook) With([]zapcore.Field) zapcore.Core {
+	return h
+}
+
+// Check 实现 zapcore.Core 接口，alertHook 只通过 alertCore 添加
+func (h alertHook) Check(_ zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce
+}
+
+// Sync 实现 zapcore.Core 接口
+func (h alertHook) Sync() error {
+	return nil
+}
+
+// Write 实现 zapcore.Core 接口
+func (h alertHook) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if a := logAlerter.Load(); a != nil {
+		a.add(ent, append(h.fields[:len(h.fields):len(h.fields)], fields...))
+	}
+	return nil
+}
+
+// webhookSink 通用 Webhook 告警
+type webhookSink struct {
+	cfg  webhookConfig
+	body *template.Template
+}
+
+// webhookClient 发送 Webhook 使用的 HTTP 客户端，不使用 NewRequest 以免请求失败的日志再次触发告警
+var webhookClient = &http.Client{Transport: defaultHTTPClient.Transport}
+
+// newWebhookSink 创建 Webhook 告警，模板中可以使用 json 函数将值编码为 JSON
+func newWebhookSink(cfg webhookConfig) (*webhookSink, error) {
+	if cfg.Method == "" {
+		cfg.Method = http.MethodPost
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = alertSendTimeout
+	}
+	if cfg.Name == "" {
+		cfg.Name = cfg.URL
+	}
+	s := &webhookSink{cfg: cfg}
+	if cfg.Body != "" {
+		tpl, err := template.New(cfg.Name).Funcs(template.FuncMap{"json": alertJSON}).Parse(cfg.Body)
+		if err != nil {
+			return nil, err
+		}
+		s.body = tpl
+	}
+	return s, nil
+}
+
+// alertJSON 模板函数，将值编码为 JSON，字符串会带引号并转义
+func alertJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// Name 实现 AlertSink 接口
+func (s *webhookSink) Name() string {
+	return s.cfg.Name
+}
+
+// Send 实现 AlertSink 接口
+func (s *webhookSink) Send(ctx context.Context, alert *Alert) error {
+	var body []byte
+	if s.body != nil {
+		var buf bytes.Buffer
+		if err := s.body.Execute(&buf, alert); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	} else {
+		var err error
+		if body, err = json.Marshal(alert); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, s.cfg.Method, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	rsp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("status %d", rsp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package gohera
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// testWebhook 记录收到的告警请求，status 不为 0 时返回该状态码
+type testWebhook struct {
+	*httptest.Server
+	mu      sync.Mutex
+	bodies  [][]byte
+	headers []http.Header
+	methods []string
+	status  int
+}
+
+func newTestWebhook(t *testing.T) *testWebhook {
+	h := new(testWebhook)
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.bodies = append(h.bodies, body)
+		h.headers = append(h.headers, r.Header.Clone())
+		h.methods = append(h.methods, r.Method)
+		if h.status != 0 {
+			w.WriteHeader(h.status)
+		}
+	}))
+	t.Cleanup(h.Close)
+	return h
+}
+
+// alerts 解析收到的 JSON 告警
+func (h *testWebhook) alerts(t *testing.T) []Alert {
+	t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	alerts := make([]Alert, len(h.bodies))
+	for i, b := range h.bodies {
+		if err := json.Unmarshal(b, &alerts[i]); err != nil {
+			t.Fatalf("invalid alert %s: %v", b, err)
+		}
+	}
+	return alerts
+}
+
+// observeAlerts 使用 cfg 开启告警，日志输出到内存中的 observer，测试结束后恢复
+func observeAlerts(t *testing.T, cfg alertConfig) (*alerter, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	saved := logger
+	logger = zap.New(&alertCore{Core: core}, zap.AddCaller()).WithOptions(zap.AddCallerSkip(2))
+	if err := applyAlertConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		logAlerter.Swap(nil).close()
+		logger = saved
+	})
+	return logAlerter.Load(), logs
+}
+
+func TestAlertAggregation(t *testing.T) {
+	hook := newTestWebhook(t)
+	a, logs := observeAlerts(t, alertConfig{Enable: true, Window: time.Hour, Throttle: time.Hour, Webhooks: []webhookConfig{{URL: hook.URL}}})
+
+	for i := 0; i < 7; i++ {
+		ctx := context.WithValue(context.Background(), TraceCtx, &Trace{TraceId: "trace-" + string(rune('a'+i))})
+		ErrorFields(ctx, "db timeout", zap.String("password", "p"))
+	}
+	Errortf(context.Background(), "cache miss")
+	Warn(context.Background(), "not alerted")
+	if n := logs.Len(); n != 9 {
+		t.Fatalf("logged %d entries, want 9", n)
+	}
+
+	// 聚合窗口内不发送
+	a.flush(time.Now())
+	if n := len(hook.alerts(t)); n != 0 {
+		t.Fatalf("sent %d alerts within the window, want 0", n)
+	}
+
+	a.flush(time.Now().Add(time.Hour))
+	alerts := hook.alerts(t)
+	if len(alerts) != 2 {
+		t.Fatalf("sent %d alerts, want 2", len(alerts))
+	}
+	byMessage := map[string]Alert{alerts[0].Message: alerts[0], alerts[1].Message: alerts[1]}
+	db := byMessage["db timeout"]
+	if db.Count != 7 || db.Level != "error" || len(db.TraceIds) != dedupSampleTraces || db.TraceIds[0] != "trace-a" {
+		t.Fatalf("alert = %+v", db)
+	}
+	if db.Fields["password"] != RedactedValue {
+		t.Fatalf("alert fields = %v, want masked password", db.Fields)
+	}
+	if byMessage["cache miss"].Count != 1 {
+		t.Fatalf("alerts = %+v", alerts)
+	}
+}
+
+func TestAlertThrottle(t *testing.T) {
+	hook := newTestWebhook(t)
+	a, _ := observeAlerts(t, alertConfig{Enable: true, Window: time.Minute, Throttle: time.Hour, Webhooks: []webhookConfig{{URL: hook.URL}}})
+
+	Error(context.Background(), "db timeout")
+	now := time.Now()
+	a.flush(now.Add(time.Minute))
+
+	// 限流期内的日志计入下一条告警
+	Error(context.Background(), "db timeout")
+	Error(context.Background(), "db timeout")
+	a.flush(now.Add(2 * time.Minute))
+	if n := len(hook.alerts(t)); n != 1 {
+		t.Fatalf("sent %d alerts within the throttle, want 1", n)
+	}
+
+	a.flush(now.Add(time.Minute + time.Hour))
+	alerts := hook.alerts(t)
+	if len(alerts) != 2 || alerts[1].Count != 2 {
+		t.Fatalf("alerts = %+v, want a second alert with count 2", alerts)
+	}
+}
+
+func TestAlertImmediateAndClose(t *testing.T) {
+	hook := newTestWebhook(t)
+	observeAlerts(t, alertConfig{Enable: true, Window: time.Hour, Webhooks: []webhookConfig{{URL: hook.URL}}})
+
+	// 高于 Error 的日志立即发送
+	logger.DPanic("dpanic")
+	if alerts := hook.alerts(t); len(alerts) != 1 || alerts[0].Level != "dpanic" || alerts[0].Count != 0 {
+		t.Fatalf("alerts = %+v, want dpanic sent immediately", alerts)
+	}
+
+	// 替换告警器时发送尚未发送的告警
+	Error(context.Background(), "pending")
+	if err := applyAlertConfig(alertConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if alerts := hook.alerts(t); len(alerts) != 2 || alerts[1].Message != "pending" {
+		t.Fatalf("alerts = %+v, want pending alert sent on close", alerts)
+	}
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	hook := newTestWebhook(t)
+	sink, err := newWebhookSink(webhookConfig{
+		URL:     hook.URL,
+		Method:  http.MethodPut,
+		Headers: map[string]string{"X-Token": "abc"},
+		Body:    `{"text": {{json .Message}}, "count": {{.Count}}}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = sink.Send(context.Background(), &Alert{Message: `say "hi"`, Count: 3}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := string(hook.bodies[0]); got != `{"text": "say \"hi\"", "count": 3}` {
+		t.Fatalf("body = %s", got)
+	}
+	if hook.methods[0] != http.MethodPut || hook.headers[0].Get("X-Token") != "abc" || hook.headers[0].Get("Content-Type") != "application/json" {
+		t.Fatalf("method = %s, headers = %v", hook.methods[0], hook.headers[0])
+	}
+
+	if _, err = newWebhookSink(webhookConfig{URL: hook.URL, Body: "{{"}); err == nil {
+		t.Fatal("newWebhookSink() should fail on invalid template")
+	}
+}
+
+// testAlertSink 自定义的告警发送目标
+type testAlertSink struct {
+	mu     sync.Mutex
+	alerts []*Alert
+}
+
+func (s *testAlertSink) Name() string { return "test" }
+
+func (s *testAlertSink) Send(_ context.Context, alert *Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts = append(s.alerts, alert)
+	return nil
+}
+
+func TestAlertSendFailure(t *testing.T) {
+	hook := newTestWebhook(t)
+	hook.status = http.StatusInternalServerError
+	sink := new(testAlertSink)
+	alertSinksMu.Lock()
+	saved := alertSinks
+	alertSinks = []AlertSink{sink}
+	alertSinksMu.Unlock()
+	t.Cleanup(func() {
+		alertSinksMu.Lock()
+		alertSinks = saved
+		alertSinksMu.Unlock()
+	})
+	a, logs := observeAlerts(t, alertConfig{Enable: true, Window: time.Hour, Webhooks: []webhookConfig{{Name: "ops", URL: hook.URL}}})
+
+	Error(context.Background(), "db timeout")
+	a.flush(time.Time{})
+
+	// 发送失败只记录日志，不会再次触发告警，其他发送目标不受影响
+	var warned bool
+	for _, e := range logs.All() {
+		if e.LoggerName == alertLoggerName && e.Level == zapcore.WarnLevel {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Fatal("send failure was not logged")
+	}
+	if len(sink.alerts) != 1 || sink.alerts[0].Message != "db timeout" {
+		t.Fatalf("custom sink alerts = %v", sink.alerts)
+	}
+	if n := len(hook.alerts(t)); n != 1 {
+		t.Fatalf("webhook received %d alerts, want 1", n)
+	}
+}
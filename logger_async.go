@@ -0,0 +1,150 @@
+package gohera
+
+import (
+	"bufio"
+	"expvar"
+	"io"
+	"slices"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// LogAsyncPolicyDrop 缓冲区满时丢弃日志
+	LogAsyncPolicyDrop = "drop"
+	// LogAsyncPolicyBlock 缓冲区满时阻塞等待
+	LogAsyncPolicyBlock = "block"
+)
+
+// asyncConfig [log.async] 配置
+type asyncConfig struct {
+	Enable        bool          `mapstructure:"enable"`
+	Cores         []string      `mapstructure:"cores" binding:"dive,oneof=debug info warn error server access"` // 使用异步写入的日志文件
+	BufferSize    int           `mapstructure:"buffer_size" binding:"gte=0"`                                    // 缓冲的日志条数
+	FlushSize     int           `mapstructure:"flush_size" binding:"gte=0"`                                     // 写入文件前合并的字节数
+	FlushInterval time.Duration `mapstructure:"flush_interval" binding:"gte=0"`                                 // 定时写入文件的间隔
+	Policy        string        `mapstructure:"policy" binding:"omitempty,oneof=drop block"`
+}
+
+// defaultAsyncConfig 默认异步写入配置，开启后 error 日志仍同步写入
+func defaultAsyncConfig() asyncConfig {
+	return asyncConfig{
+		Cores:         []string{"debug", "info", "warn", "server", "access"},
+		BufferSize:    8192,
+		FlushSize:     256 * 1024,
+		FlushInterval: time.Second,
+		Policy:        LogAsyncPolicyDrop,
+	}
+}
+
+// enabled 日志文件是否使用异步写入
+func (c asyncConfig) enabled(core string) bool {
+	return c.Enable && slices.Contains(c.Cores, core)
+}
+
+// logDropped 各日志文件因缓冲区满而丢弃的日志条数，通过 /metrics 输出
+var logDropped = expvar.NewMap("log_dropped")
+
+// LogDropped 获取所有日志文件因缓冲区满而丢弃的日志条数
+func LogDropped() int64 {
+	var total int64
+	logDropped.Do(func(kv expvar.KeyValue) {
+		if v, ok := kv.Value.(*expvar.Int); ok {
+			total += v.Value()
+		}
+	})
+	return total
+}
+
+// asyncWriter 异步写入日志
+// 日志先写入有界缓冲区，由后台协程合并后写入文件，缓冲区满时按策略丢弃或阻塞
+type asyncWriter struct {
+	name    string
+	out     io.Writer
+	buf     *bufio.Writer
+	entries chan []byte
+	syncs   chan chan error
+	block   bool
+}
+
+// newAsyncWriter 创建异步写入器并启动后台写入协程
+func newAsyncWriter(name string, out io.Writer, cfg asyncConfig) *asyncWriter {
+	def := defaultAsyncConfig()
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = def.BufferSize
+	}
+	if cfg.FlushSize <= 0 {
+		cfg.FlushSize = def.FlushSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = def.FlushInterval
+	}
+	w := &asyncWriter{
+		name:    name,
+		out:     out,
+		buf:     bufio.NewWriterSize(out, cfg.FlushSize),
+		entries: make(chan []byte, cfg.BufferSize),
+		syncs:   make(chan chan error),
+		block:   cfg.Policy == LogAsyncPolicyBlock,
+	}
+	go w.run(cfg.FlushInterval)
+	return w
+}
+
+// Write 实现 zapcore.WriteSyncer 接口，zap 会复用 p，因此需要复制后写入缓冲区
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	entry := make([]byte, len(p))
+	copy(entry, p)
+	if w.block {
+		w.entries <- entry
+		return len(p), nil
+	}
+	select {
+	case w.entries <- entry:
+	default:
+		logDropped.Add(w.name, 1)
+	}
+	return len(p), nil
+}
+
+// Sync 实现 zapcore.WriteSyncer 接口，等待缓冲区中的日志全部写入文件
+func (w *asyncWriter) Sync() error {
+	done := make(chan error)
+	w.syncs <- done
+	return <-done
+}
+
+// run 后台写入协程
+func (w *asyncWriter) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case entry := <-w.entries:
+			_, _ = w.buf.Write(entry)
+		case <-ticker.C:
+			_ = w.buf.Flush()
+		case done := <-w.syncs:
+			done <- w.drain()
+		}
+	}
+}
+
+// drain 写入缓冲区中剩余的日志并刷新到文件
+func (w *asyncWriter) drain() error {
+	for {
+		select {
+		case entry := <-w.entries:
+			_, _ = w.buf.Write(entry)
+		default:
+			if err := w.buf.Flush(); err != nil {
+				return err
+			}
+			if s, ok := w.out.(zapcore.WriteSyncer); ok {
+				return s.Sync()
+			}
+			return nil
+		}
+	}
+}
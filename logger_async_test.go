@@ -0,0 +1,149 @@
+package gohera
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testLogOutput 并发安全的日志输出，设置 gate 后每次写入都会阻塞到 gate 关闭
+type testLogOutput struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	gate    chan struct{}
+	writing chan struct{}
+	syncs   int
+}
+
+func (o *testLogOutput) Write(p []byte) (int, error) {
+	if o.gate != nil {
+		select {
+		case o.writing <- struct{}{}:
+		default:
+		}
+		<-o.gate
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.Write(p)
+}
+
+func (o *testLogOutput) Sync() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.syncs++
+	return nil
+}
+
+func (o *testLogOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.String()
+}
+
+func TestAsyncWriterSync(t *testing.T) {
+	out := new(testLogOutput)
+	w := newAsyncWriter("test_sync", out, asyncConfig{FlushInterval: time.Hour})
+	p := []byte("line 1\n")
+	_, _ = w.Write(p)
+	// zap 会复用 p，写入后修改不影响已写入的内容
+	copy(p, "LINE 9\n")
+	_, _ = w.Write([]byte("line 2\n"))
+
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if got := out.String(); got != "line 1\nline 2\n" {
+		t.Fatalf("output = %q", got)
+	}
+	if out.syncs != 1 {
+		t.Fatalf("output synced %d times, want 1", out.syncs)
+	}
+}
+
+func TestAsyncWriterFlushInterval(t *testing.T) {
+	out := new(testLogOutput)
+	w := newAsyncWriter("test_interval", out, asyncConfig{FlushInterval: 10 * time.Millisecond})
+	_, _ = w.Write([]byte("line 1\n"))
+	deadline := time.Now().Add(2 * time.Second)
+	for out.String() != "line 1\n" {
+		if time.Now().After(deadline) {
+			t.Fatal("buffered log was not flushed by the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAsyncWriterDrop(t *testing.T) {
+	out := &testLogOutput{gate: make(chan struct{}), writing: make(chan struct{}, 1)}
+	w := newAsyncWriter("test_drop", out, asyncConfig{BufferSize: 1, FlushSize: 1, FlushInterval: time.Hour})
+	before := LogDropped()
+
+	// 第一条日志写入时阻塞，第二条进入缓冲区，之后的日志被丢弃
+	_, _ = w.Write([]byte("line 1\n"))
+	<-out.writing
+	for i := 2; i <= 5; i++ {
+		if n, err := w.Write([]byte("line\n")); n != 5 || err != nil {
+			t.Fatalf("Write() = %d, %v", n, err)
+		}
+	}
+	if got := LogDropped() - before; got != 3 {
+		t.Fatalf("dropped = %d, want 3", got)
+	}
+	if logDropped.Get("test_drop") == nil {
+		t.Fatal("log_dropped[test_drop] is not recorded")
+	}
+
+	close(out.gate)
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(out.String(), "\n"); got != 2 {
+		t.Fatalf("written %d lines, want 2", got)
+	}
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	out := &testLogOutput{gate: make(chan struct{}), writing: make(chan struct{}, 1)}
+	w := newAsyncWriter("test_block", out, asyncConfig{BufferSize: 1, FlushSize: 1, FlushInterval: time.Hour, Policy: LogAsyncPolicyBlock})
+
+	_, _ = w.Write([]byte("line 1\n"))
+	<-out.writing
+	_, _ = w.Write([]byte("line 2\n"))
+	written := make(chan struct{})
+	go func() {
+		_, _ = w.Write([]byte("line 3\n"))
+		close(written)
+	}()
+	// 缓冲区满时阻塞等待
+	select {
+	case <-written:
+		t.Fatal("Write() should block when the buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(out.gate)
+	<-written
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "line 1\nline 2\nline 3\n" {
+		t.Fatalf("output = %q", got)
+	}
+	if logDropped.Get("test_block") != nil {
+		t.Fatal("block policy should not drop logs")
+	}
+}
+
+func TestAsyncConfigEnabled(t *testing.T) {
+	cfg := defaultAsyncConfig()
+	if cfg.enabled("info") {
+		t.Fatal("async should be disabled by default")
+	}
+	cfg.Enable = true
+	if !cfg.enabled("info") || !cfg.enabled("access") || cfg.enabled("error") {
+		t.Fatal("error logs should be written synchronously by default")
+	}
+}
@@ -0,0 +1,147 @@
+package gohera
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/metlive/gohera/rotatelogs"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// LogOutputFile 日志输出到文件
+	LogOutputFile = "file"
+	// LogOutputConsole 日志以 JSON 格式输出到标准输出
+	LogOutputConsole = "console"
+	// LogOutputBoth 日志同时输出到文件及标准输出
+	LogOutputBoth = "both"
+
+	// DefaultLogFilePattern 日志文件名默认的时间后缀
+	DefaultLogFilePattern = "%Y-%m-%d"
+	// DefaultLogTimeFormat 日志时间默认的格式
+	DefaultLogTimeFormat = "2006-01-02 15:04:05"
+)
+
+// loggerConfig [log] 配置
+type loggerConfig struct {
+	Path         string            `mapstructure:"path"`
+	Level        string            `mapstructure:"level" binding:"omitempty,oneof=debug info warn error dpanic panic fatal"`        // 全局日志级别
+	Modules      map[string]string `mapstructure:"modules" binding:"dive,oneof=debug info warn error dpanic panic fatal"`           // 模块的日志级别
+	Stdout       bool              `mapstructure:"stdout"`                                                                          // 控制台输出不含字段的纯文本，便于本地开发
+	Output       string            `mapstructure:"output" binding:"omitempty,oneof=file console both"`                              // 日志输出位置
+	StderrLevel  string            `mapstructure:"stderr_level" binding:"omitempty,oneof=debug info warn error dpanic panic fatal"` // 该级别及以上的日志输出到标准错误
+	FilePattern  string            `mapstructure:"file_pattern"`                                                                    // 轮转文件名的时间后缀，strftime 格式
+	RotationTime time.Duration     `mapstructure:"rotation_time" binding:"gte=0"`                                                   // 按时间轮转的间隔
+	MaxSize      int               `mapstructure:"max_size" binding:"gte=0"`                                                        // 单个文件的最大大小 (MB)，0 表示不按大小轮转
+	MaxAge       time.Duration     `mapstructure:"max_age" binding:"gte=0"`                                                         // 日志文件的保留时长
+	MaxBackups   int               `mapstructure:"max_backups" binding:"gte=0"`                                                     // 日志文件的保留个数，设置后 max_age 不生效
+	Compress     bool              `mapstructure:"compress"`                                                                        // 是否压缩轮转后的日志文件
+	Split        bool              `mapstructure:"split"`                                                                           // 是否按级别输出到不同的文件
+	TimeFormat   string            `mapstructure:"time_format"`
+	Keys         loggerKeys        `mapstructure:"keys"`
+	Mask         maskConfig        `mapstructure:"mask"`
+	Async        asyncConfig       `mapstructure:"async"`
+	Access       accessLogConfig   `mapstructure:"access"`
+	Sampling     samplingConfig    `mapstructure:"sampling"`
+	Alert        alertConfig       `mapstructure:"alert"`
+	Mysql        dbLogConfig       `mapstructure:"mysql"`
+	Redis        dbLogConfig       `mapstructure:"redis"`
+
+	FilePath string `mapstructure:"-"` // 日志目录，path/应用名
+}
+
+// loggerKeys 日志 JSON 中内置字段的名称
+type loggerKeys struct {
+	Message    string `mapstructure:"message"`
+	Level      string `mapstructure:"level"`
+	Time       string `mapstructure:"time"`
+	Logger     string `mapstructure:"logger"`
+	Caller     string `mapstructure:"caller"`
+	Stacktrace string `mapstructure:"stacktrace"`
+}
+
+// defaultLoggerConfig 默认日志配置，与未提供 [log] 配置时的行为一致
+func defaultLoggerConfig() loggerConfig {
+	return loggerConfig{
+		Path:         DefaultLogPath,
+		Output:       LogOutputFile,
+		FilePattern:  DefaultLogFilePattern,
+		RotationTime: 24 * time.Hour,
+		MaxAge:       7 * 24 * time.Hour,
+		Split:        true,
+		TimeFormat:   DefaultLogTimeFormat,
+		Keys: loggerKeys{
+			Message:    "x_message",
+			Level:      "x_level",
+			Time:       "x_time",
+			Logger:     "x_logger",
+			Caller:     "x_caller",
+			Stacktrace: "x_trace",
+		},
+		Mask:     defaultMaskConfig(),
+		Async:    defaultAsyncConfig(),
+		Access:   defaultAccessLogConfig(),
+		Sampling: defaultSamplingConfig(),
+		Alert:    defaultAlertConfig(),
+		Mysql:    defaultMysqlLogConfig(),
+		Redis:    defaultRedisLogConfig(),
+	}
+}
+
+// loadLoggerConfig 读取 [log] 配置，未配置的项使用默认值
+func loadLoggerConfig() (loggerConfig, error) {
+	cfg := defaultLoggerConfig()
+	if IsSet("log") {
+		if err := UnmarshalKey("log", &cfg); err != nil {
+			return cfg, fmt.Errorf("log config parse fail: %w", err)
+		}
+	}
+	if cfg.MaxBackups > 0 {
+		// rotatelogs 中保留时长与保留个数不能同时设置
+		cfg.MaxAge = 0
+	}
+	cfg.FilePath = filepath.Join(cfg.Path, appName)
+	return cfg, nil
+}
+
+// rotateOptions 日志文件的轮转选项
+func (c loggerConfig) rotateOptions(fileName string) []rotatelogs.Option {
+	opts := []rotatelogs.Option{
+		rotatelogs.WithLinkName(fileName),
+		rotatelogs.WithCompress(c.Compress),
+	}
+	if c.RotationTime > 0 {
+		opts = append(opts, rotatelogs.WithRotationTime(c.RotationTime))
+	}
+	if c.MaxSize > 0 {
+		opts = append(opts, rotatelogs.WithRotationSize(int64(c.MaxSize)*1024*1024))
+	}
+	if c.MaxBackups > 0 {
+		opts = append(opts, rotatelogs.WithRotationCount(uint(c.MaxBackups)))
+	} else if c.MaxAge > 0 {
+		opts = append(opts, rotatelogs.WithMaxAge(c.MaxAge))
+	}
+	return opts
+}
+
+// encoderConfig 日志文件的 JSON 编码配置
+func (c loggerConfig) encoderConfig() zapcore.EncoderConfig {
+	timeFormat := c.TimeFormat
+	return zapcore.EncoderConfig{
+		MessageKey:    c.Keys.Message,
+		LevelKey:      c.Keys.Level,
+		StacktraceKey: c.Keys.Stacktrace,
+		TimeKey:       c.Keys.Time,
+		NameKey:       c.Keys.Logger,
+		CallerKey:     c.Keys.Caller,
+		LineEnding:    zapcore.DefaultLineEnding,
+		EncodeLevel:   zapcore.LowercaseLevelEncoder,
+		EncodeTime: func(t time.Time, encoder zapcore.PrimitiveArrayEncoder) {
+			encoder.AppendString(t.Format(timeFormat))
+		},
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+		EncodeCaller:   zapcore.FullCallerEncoder,
+		EncodeName:     zapcore.FullNameEncoder,
+	}
+}
@@ -0,0 +1,81 @@
+package gohera
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadLoggerConfigDefaults(t *testing.T) {
+	loadTestConfig(t, "", map[string]string{"app.toml": "[http]\nport = 8080\n"})
+	cfg, err := loadLoggerConfig()
+	if err != nil {
+		t.Fatalf("loadLoggerConfig() error = %v", err)
+	}
+	want := defaultLoggerConfig()
+	want.FilePath = filepath.Join(DefaultLogPath, appName)
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("loadLoggerConfig() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadLoggerConfigOverrides(t *testing.T) {
+	dir := t.TempDir()
+	loadTestConfig(t, "", map[string]string{"app.toml": `
+[log]
+path = "` + filepath.ToSlash(dir) + `"
+level = "warn"
+output = "both"
+rotation_time = "1h"
+max_size = 100
+max_backups = 10
+compress = true
+split = false
+time_format = "2006-01-02T15:04:05Z07:00"
+[log.keys]
+message = "msg"
+[log.modules]
+mysql = "debug"
+[log.async]
+enable = true
+`})
+	cfg, err := loadLoggerConfig()
+	if err != nil {
+		t.Fatalf("loadLoggerConfig() error = %v", err)
+	}
+	if cfg.Path != dir || cfg.FilePath != filepath.Join(dir, appName) {
+		t.Errorf("path = %s, file path = %s", cfg.Path, cfg.FilePath)
+	}
+	if cfg.Level != "warn" || cfg.Modules["mysql"] != "debug" || cfg.Output != LogOutputBoth {
+		t.Errorf("level = %s, modules = %v, output = %s", cfg.Level, cfg.Modules, cfg.Output)
+	}
+	if cfg.RotationTime != time.Hour || cfg.MaxSize != 100 || cfg.MaxBackups != 10 || !cfg.Compress || cfg.Split {
+		t.Errorf("rotation = %+v", cfg)
+	}
+	// 设置 max_backups 后 max_age 不生效
+	if cfg.MaxAge != 0 {
+		t.Errorf("max_age = %v, want 0 when max_backups is set", cfg.MaxAge)
+	}
+	// 未配置的子项保留默认值
+	if cfg.Keys.Message != "msg" || cfg.Keys.Level != "x_level" {
+		t.Errorf("keys = %+v", cfg.Keys)
+	}
+	if !cfg.Async.Enable || cfg.Async.BufferSize != defaultAsyncConfig().BufferSize {
+		t.Errorf("async = %+v", cfg.Async)
+	}
+	if got := len(cfg.rotateOptions("info.log")); got != 5 {
+		t.Errorf("rotateOptions() = %d options, want 5", got)
+	}
+	enc := cfg.encoderConfig()
+	if enc.MessageKey != "msg" || enc.TimeKey != "x_time" {
+		t.Errorf("encoderConfig() = %+v", enc)
+	}
+}
+
+func TestLoadLoggerConfigInvalid(t *testing.T) {
+	loadTestConfig(t, "", map[string]string{"app.toml": "[log]\nmax_size = \"big\"\n"})
+	if _, err := loadLoggerConfig(); err == nil {
+		t.Fatal("loadLoggerConfig() should fail on invalid max_size")
+	}
+}
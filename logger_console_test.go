@@ -0,0 +1,107 @@
+package gohera
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// captureConsole 使用 cfg 初始化日志，标准输出及标准错误写入内存，测试结束后恢复
+func captureConsole(t *testing.T, cfg loggerConfig) (stdout, stderr *bytes.Buffer) {
+	resetLogLevels(t)
+	stdout, stderr = new(bytes.Buffer), new(bytes.Buffer)
+	savedStdout, savedStderr := consoleStdout, consoleStderr
+	savedLogger, savedAccess := logger, accessLogger
+	consoleStdout, consoleStderr = zapcore.AddSync(stdout), zapcore.AddSync(stderr)
+	t.Cleanup(func() {
+		consoleStdout, consoleStderr = savedStdout, savedStderr
+		logger, accessLogger = savedLogger, savedAccess
+	})
+	if err := initLoggerPool(cfg); err != nil {
+		t.Fatalf("initLoggerPool() error = %v", err)
+	}
+	return stdout, stderr
+}
+
+// consoleMessages 解析 JSON 日志，返回 x_message 字段
+func consoleMessages(t *testing.T, buf *bytes.Buffer) []string {
+	t.Helper()
+	var msgs []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		m := make(map[string]any)
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		msgs = append(msgs, m["x_message"].(string))
+	}
+	return msgs
+}
+
+func TestConsoleOutput(t *testing.T) {
+	cfg := defaultLoggerConfig()
+	cfg.Output = LogOutputConsole
+	cfg.FilePath = filepath.Join(t.TempDir(), "app")
+	stdout, stderr := captureConsole(t, cfg)
+
+	Info(context.Background(), "info")
+	Error(context.Background(), "error 13812345678")
+
+	if got := strings.Join(consoleMessages(t, stdout), ","); got != "info,error 138****5678" {
+		t.Fatalf("stdout = %s", got)
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("stderr = %q, want empty without stderr_level", stderr.String())
+	}
+	// console 模式不写入日志文件
+	if _, err := os.Stat(cfg.FilePath); !os.IsNotExist(err) {
+		t.Fatalf("log dir should not be created, stat error = %v", err)
+	}
+}
+
+func TestConsoleOutputStderrLevel(t *testing.T) {
+	cfg := defaultLoggerConfig()
+	cfg.Output = LogOutputConsole
+	cfg.StderrLevel = "warn"
+	cfg.Split = false
+	stdout, stderr := captureConsole(t, cfg)
+
+	Debug(context.Background(), "debug")
+	Info(context.Background(), "info")
+	Warn(context.Background(), "warn")
+	Error(context.Background(), "error")
+
+	if got := strings.Join(consoleMessages(t, stdout), ","); got != "debug,info" {
+		t.Fatalf("stdout = %s, want debug,info", got)
+	}
+	if got := strings.Join(consoleMessages(t, stderr), ","); got != "warn,error" {
+		t.Fatalf("stderr = %s, want warn,error", got)
+	}
+}
+
+func TestConsoleOutputBoth(t *testing.T) {
+	cfg := defaultLoggerConfig()
+	cfg.Output = LogOutputBoth
+	cfg.FilePath = filepath.Join(t.TempDir(), "app")
+	stdout, _ := captureConsole(t, cfg)
+
+	Info(context.Background(), "both")
+	if got := strings.Join(consoleMessages(t, stdout), ","); got != "both" {
+		t.Fatalf("stdout = %s", got)
+	}
+	data, err := os.ReadFile(filepath.Join(cfg.FilePath, "server_info.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"x_message":"both"`) {
+		t.Fatalf("server_info.log = %s", data)
+	}
+}
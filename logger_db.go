@@ -0,0 +1,161 @@
+package gohera
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/metlive/gohera/mysql"
+	"github.com/metlive/gohera/redis"
+	"go.uber.org/zap"
+)
+
+// dbLogConfig [log.mysql]、[log.redis] 配置
+type dbLogConfig struct {
+	Enable        bool          `mapstructure:"enable"`
+	Verbose       bool          `mapstructure:"verbose"`                        // 以 Debug 级别记录所有语句，否则只记录慢查询及错误
+	Args          bool          `mapstructure:"args"`                           // 是否记录参数，参数按脱敏规则脱敏
+	SlowThreshold time.Duration `mapstructure:"slow_threshold" binding:"gte=0"` // 慢查询阈值，超过时以 Warn 级别记录，0 表示不记录慢查询
+}
+
+// defaultMysqlLogConfig 默认 SQL 日志配置，开发及测试环境记录所有语句
+func defaultMysqlLogConfig() dbLogConfig {
+	return dbLogConfig{
+		Enable:        true,
+		Verbose:       IsDev() || IsTest(),
+		Args:          true,
+		SlowThreshold: 200 * time.Millisecond,
+	}
+}
+
+// defaultRedisLogConfig 默认 Redis 命令日志配置，开发及测试环境记录所有命令
+func defaultRedisLogConfig() dbLogConfig {
+	return dbLogConfig{
+		Enable:        true,
+		Verbose:       IsDev() || IsTest(),
+		Args:          true,
+		SlowThreshold: 50 * time.Millisecond,
+	}
+}
+
+// dbLogArgMaxSize 单个参数记录的最大长度
+const dbLogArgMaxSize = 256
+
+var (
+	mysqlLogConf atomic.Pointer[dbLogConfig]
+	redisLogConf atomic.Pointer[dbLogConfig]
+
+	mysqlLogger = Named("mysql")
+	redisLogger = Named("redis")
+)
+
+func init() {
+	// 日志初始化前不记录
+	mysqlLogConf.Store(&dbLogConfig{})
+	redisLogConf.Store(&dbLogConfig{})
+	mysql.AddHook(mysqlLogHook{})
+	redis.AddHook(redisLogHook{})
+}
+
+// applyDbLogConfig 应用 SQL 及 Redis 命令日志配置
+func applyDbLogConfig(cfg loggerConfig) {
+	mysqlLogConf.Store(&cfg.Mysql)
+	redisLogConf.Store(&cfg.Redis)
+}
+
+// watchDbLogConfig 配置热更新时重新应用 SQL 及 Redis 命令日志配置
+func watchDbLogConfig() {
+	fn := func(_, _ any) {
+		cfg, err := loadLoggerConfig()
+		if err != nil {
+			Errortf(context.Background(), "log db config fail: %v", err)
+			return
+		}
+		applyDbLogConfig(cfg)
+	}
+	OnChange("log.mysql", fn)
+	OnChange("log.redis", fn)
+}
+
+// dbLogArgs 将参数转换为脱敏后的字符串，超过最大长度的部分截断
+func dbLogArgs(args []any) []string {
+	list := make([]string, len(args))
+	for i, arg := range args {
+		var s string
+		switch v := arg.(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		default:
+			s = fmt.Sprint(v)
+		}
+		if len(s) > dbLogArgMaxSize {
+			s = s[:dbLogArgMaxSize] + "..."
+		}
+		list[i] = MaskString(s)
+	}
+	return list
+}
+
+// logDbCall 按配置记录一次 SQL 或 Redis 命令，出错时以 Error 级别记录，超过慢查询阈值时以 Warn 级别记录
+func logDbCall(ctx context.Context, m *ModuleLogger, conf *dbLogConfig, msg, kind string, args []any, duration time.Duration, err error, fields ...zap.Field) {
+	if !conf.Enable {
+		return
+	}
+	slow := conf.SlowThreshold > 0 && duration >= conf.SlowThreshold
+	if err == nil && !slow && !conf.Verbose {
+		return
+	}
+	fields = append(fields, zap.Float64("x_duration", float64(duration.Microseconds())/1000))
+	if conf.Args && len(args) > 0 {
+		fields = append(fields, zap.Strings("x_args", dbLogArgs(args)))
+	}
+	switch {
+	case err != nil:
+		fields = append(fields, zap.String("x_error", err.Error()))
+		m.ErrorFields(ctx, msg, fields...)
+	case slow:
+		m.WarnFields(ctx, "slow "+kind+": "+msg, fields...)
+	default:
+		m.DebugFields(ctx, msg, fields...)
+	}
+}
+
+// mysqlLogHook 记录 SQL 日志，Context 中的 Trace 信息随日志输出
+type mysqlLogHook struct{}
+
+// BeforeQuery 实现 mysql.Hook 接口
+func (mysqlLogHook) BeforeQuery(ctx context.Context, _ string, _ []any) context.Context {
+	return ctx
+}
+
+// AfterQuery 实现 mysql.Hook 接口，sql.ErrNoRows 不视为错误
+func (mysqlLogHook) AfterQuery(ctx context.Context, query string, args []any, result sql.Result, duration time.Duration, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	var fields []zap.Field
+	if result != nil {
+		if rows, e := result.RowsAffected(); e == nil {
+			fields = append(fields, zap.Int64("x_rows", rows))
+		}
+	}
+	logDbCall(ctx, mysqlLogger, mysqlLogConf.Load(), query, "query", args, duration, err, fields...)
+}
+
+// redisLogHook 记录 Redis 命令日志，Context 中的 Trace 信息随日志输出
+type redisLogHook struct{}
+
+// BeforeProcess 实现 redis.Hook 接口
+func (redisLogHook) BeforeProcess(ctx context.Context, _ string, _ []any) context.Context {
+	return ctx
+}
+
+// AfterProcess 实现 redis.Hook 接口
+func (redisLogHook) AfterProcess(ctx context.Context, cmd string, args []any, duration time.Duration, err error) {
+	logDbCall(ctx, redisLogger, redisLogConf.Load(), cmd, "command", args, duration, err)
+}
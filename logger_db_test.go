@@ -0,0 +1,105 @@
+package gohera
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// testSqlResult 固定影响行数的 sql.Result
+type testSqlResult int64
+
+func (r testSqlResult) LastInsertId() (int64, error) { return 0, nil }
+func (r testSqlResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+// observeDbLogs 使用 mysql 及 redis 的日志配置，日志输出到内存中的 observer，测试结束后恢复
+func observeDbLogs(t *testing.T, mysqlConf, redisConf dbLogConfig) *observer.ObservedLogs {
+	logs := observeLogs(t)
+	savedMysql, savedRedis := mysqlLogConf.Load(), redisLogConf.Load()
+	mysqlLogConf.Store(&mysqlConf)
+	redisLogConf.Store(&redisConf)
+	t.Cleanup(func() {
+		mysqlLogConf.Store(savedMysql)
+		redisLogConf.Store(savedRedis)
+	})
+	return logs
+}
+
+func TestLogDbCallLevels(t *testing.T) {
+	conf := dbLogConfig{Enable: true, Args: true, SlowThreshold: 100 * time.Millisecond}
+	logs := observeDbLogs(t, conf, conf)
+	ctx := testTraceContext()
+	query := "SELECT * FROM users WHERE phone = ?"
+
+	// 非 verbose 时不记录正常的语句，sql.ErrNoRows 不视为错误
+	mysqlLogHook{}.AfterQuery(ctx, query, []any{"13812345678"}, nil, time.Millisecond, nil)
+	mysqlLogHook{}.AfterQuery(ctx, query, []any{"13812345678"}, nil, time.Millisecond, sql.ErrNoRows)
+	if n := logs.Len(); n != 0 {
+		t.Fatalf("logged %d entries, want 0", n)
+	}
+
+	mysqlLogHook{}.AfterQuery(ctx, query, []any{"13812345678", 42}, testSqlResult(3), 150*time.Millisecond, nil)
+	mysqlLogHook{}.AfterQuery(ctx, query, nil, nil, time.Millisecond, errors.New("bad connection"))
+	redisLogHook{}.AfterProcess(ctx, "get", []any{"get", "user:1"}, 200*time.Millisecond, nil)
+
+	entries := logs.TakeAll()
+	if len(entries) != 3 {
+		t.Fatalf("logged %d entries, want 3", len(entries))
+	}
+	slow, failed, redis := entries[0], entries[1], entries[2]
+	m := slow.ContextMap()
+	if slow.Level != zapcore.WarnLevel || slow.Message != "slow query: "+query || slow.LoggerName != "mysql" {
+		t.Fatalf("entry = %s %s %q", slow.LoggerName, slow.Level, slow.Message)
+	}
+	if m["x_trace_id"] != "trace-1" || m["x_rows"] != int64(3) || m["x_duration"] != float64(150) {
+		t.Fatalf("fields = %v", m)
+	}
+	if args, _ := m["x_args"].([]any); len(args) != 2 || args[0] != "138****5678" || args[1] != "42" {
+		t.Fatalf("x_args = %v", m["x_args"])
+	}
+	if m = failed.ContextMap(); failed.Level != zapcore.ErrorLevel || failed.Message != query || m["x_error"] != "bad connection" {
+		t.Fatalf("entry = %s %q %v", failed.Level, failed.Message, m)
+	}
+	if _, ok := m["x_args"]; ok {
+		t.Fatal("x_args should be omitted without args")
+	}
+	if redis.Level != zapcore.WarnLevel || redis.Message != "slow command: get" || redis.LoggerName != "redis" {
+		t.Fatalf("entry = %s %s %q", redis.LoggerName, redis.Level, redis.Message)
+	}
+}
+
+func TestLogDbCallVerbose(t *testing.T) {
+	logs := observeDbLogs(t, dbLogConfig{Enable: true, Verbose: true}, dbLogConfig{})
+
+	mysqlLogHook{}.AfterQuery(testTraceContext(), "UPDATE users SET name = ?", []any{"a"}, testSqlResult(1), time.Millisecond, nil)
+	redisLogHook{}.AfterProcess(testTraceContext(), "get", []any{"get", "k"}, time.Second, errors.New("redis down"))
+
+	// 未设置慢查询阈值时不记录慢查询，未开启 args 时不记录参数，redis 未开启时不记录
+	entries := logs.TakeAll()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	m := entries[0].ContextMap()
+	if entries[0].Level != zapcore.DebugLevel || m["x_rows"] != int64(1) {
+		t.Fatalf("entry = %s %v", entries[0].Level, m)
+	}
+	if _, ok := m["x_args"]; ok {
+		t.Fatal("x_args should be omitted when args is disabled")
+	}
+}
+
+func TestDbLogArgs(t *testing.T) {
+	long := strings.Repeat("a", dbLogArgMaxSize+10)
+	got := dbLogArgs([]any{[]byte("bytes"), 1.5, nil, long})
+	if got[0] != "bytes" || got[1] != "1.5" || got[2] != "<nil>" {
+		t.Fatalf("dbLogArgs() = %v", got)
+	}
+	if got[3] != long[:dbLogArgMaxSize]+"..." {
+		t.Fatalf("long arg has %d bytes, want truncated", len(got[3]))
+	}
+}
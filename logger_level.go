@@ -0,0 +1,256 @@
+package gohera
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+// logLevelState 当前生效的日志级别，更新时整体替换
+type logLevelState struct {
+	global  zapcore.Level
+	modules map[string]zapcore.Level
+	min     zapcore.Level // 所有级别中的最低级别，用于快速判断
+}
+
+var (
+	logLevels      atomic.Pointer[logLevelState]
+	logLevelMu     sync.Mutex
+	logLevelBase   = logLevelState{modules: map[string]zapcore.Level{}} // 配置文件中的级别，临时调整到期后恢复
+	logLevelTimers = map[string]*time.Timer{}
+)
+
+func init() {
+	logLevels.Store(&logLevelState{global: zapcore.DebugLevel, modules: map[string]zapcore.Level{}, min: zapcore.DebugLevel})
+	logLevelBase.global = zapcore.DebugLevel
+}
+
+// level 获取模块生效的日志级别，未单独设置的模块使用全局级别
+func (s *logLevelState) level(module string) zapcore.Level {
+	if module != "" {
+		if l, ok := s.modules[module]; ok {
+			return l
+		}
+	}
+	return s.global
+}
+
+// storeLogLevels 更新生效的日志级别，调用方需持有 logLevelMu
+func storeLogLevels(global zapcore.Level, modules map[string]zapcore.Level) {
+	s := &logLevelState{global: global, modules: modules, min: global}
+	for _, l := range modules {
+		if l < s.min {
+			s.min = l
+		}
+	}
+	logLevels.Store(s)
+}
+
+// GetLogLevel 获取日志级别，module 为空时返回全局级别
+func GetLogLevel(module string) zapcore.Level {
+	return logLevels.Load().level(module)
+}
+
+// LogLevels 获取全局及所有模块的日志级别
+func LogLevels() (global zapcore.Level, modules map[string]zapcore.Level) {
+	s := logLevels.Load()
+	return s.global, maps.Clone(s.modules)
+}
+
+// SetLogLevel 调整日志级别，module 为空时调整全局级别
+// ttl 大于 0 时到期后恢复为配置文件中的级别，用于临时开启 Debug 日志排查问题
+func SetLogLevel(module string, level zapcore.Level, ttl time.Duration) {
+	logLevelMu.Lock()
+	defer logLevelMu.Unlock()
+	setLogLevel(module, level, ttl)
+}
+
+// setLogLevel 调整日志级别，调用方需持有 logLevelMu
+func setLogLevel(module string, level zapcore.Level, ttl time.Duration) {
+	s := logLevels.Load()
+	global, modules := s.global, maps.Clone(s.modules)
+	if module == "" {
+		global = level
+	} else {
+		modules[module] = level
+	}
+	storeLogLevels(global, modules)
+
+	if t, ok := logLevelTimers[module]; ok {
+		t.Stop()
+		delete(logLevelTimers, module)
+	}
+	if ttl > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(ttl, func() {
+			logLevelMu.Lock()
+			defer logLevelMu.Unlock()
+			// 到期时已被新的调整替换（Stop 无法取消已触发的定时器）则不再恢复
+			if logLevelTimers[module] == timer {
+				revertLogLevel(module)
+			}
+		})
+		logLevelTimers[module] = timer
+	}
+}
+
+// revertLogLevel 将模块的日志级别恢复为配置文件中的级别，调用方需持有 logLevelMu
+func revertLogLevel(module string) {
+	delete(logLevelTimers, module)
+	s := logLevels.Load()
+	global, modules := s.global, maps.Clone(s.modules)
+	if module == "" {
+		global = logLevelBase.global
+	} else if l, ok := logLevelBase.modules[module]; ok {
+		modules[module] = l
+	} else {
+		delete(modules, module)
+	}
+	storeLogLevels(global, modules)
+	Infotf(context.Background(), "log level of %s reverted to %s", logModuleName(module), logLevels.Load().level(module))
+}
+
+// applyLogLevelConfig 应用 [log] 中 level 及 modules 配置
+// 未到期的临时调整保持不变，到期后恢复为新的配置；不带 ttl 的调整被配置覆盖
+func applyLogLevelConfig(cfg loggerConfig) error {
+	global, modules, err := parseLogLevelConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	logLevelMu.Lock()
+	defer logLevelMu.Unlock()
+	logLevelBase = logLevelState{global: global, modules: modules}
+	s := logLevels.Load()
+	effective := maps.Clone(modules)
+	for module := range logLevelTimers {
+		if module == "" {
+			global = s.global
+		} else if l, ok := s.modules[module]; ok {
+			effective[module] = l
+		}
+	}
+	storeLogLevels(global, effective)
+	return nil
+}
+
+// parseLogLevelConfig 解析配置中的日志级别
+func parseLogLevelConfig(cfg loggerConfig) (zapcore.Level, map[string]zapcore.Level, error) {
+	global := zapcore.DebugLevel
+	if cfg.Level != "" {
+		if err := global.Set(cfg.Level); err != nil {
+			return global, nil, fmt.Errorf("log.level: %w", err)
+		}
+	}
+	modules := make(map[string]zapcore.Level, len(cfg.Modules))
+	for module, level := range cfg.Modules {
+		var l zapcore.Level
+		if err := l.Set(level); err != nil {
+			return global, nil, fmt.Errorf("log.modules.%s: %w", module, err)
+		}
+		modules[module] = l
+	}
+	return global, modules, nil
+}
+
+// watchLogLevelConfig 配置中 log.level 或 log.modules 热更新时重新应用日志级别
+func watchLogLevelConfig() {
+	apply := func(_, _ any) {
+		cfg, err := loadLoggerConfig()
+		if err == nil {
+			err = applyLogLevelConfig(cfg)
+		}
+		if err != nil {
+			Errortf(context.Background(), "log level config fail: %v", err)
+			return
+		}
+		Infotf(context.Background(), "log level changed by config: %s", cfg.Level)
+	}
+	OnChange("log.level", apply)
+	OnChange("log.modules", apply)
+}
+
+func logModuleName(module string) string {
+	if module == "" {
+		return "global"
+	}
+	return module
+}
+
+// levelFilterCore 按模块的日志级别过滤日志
+// 被包装的 Core 只负责按级别拆分文件，是否输出由全局及模块级别决定
+type levelFilterCore struct {
+	zapcore.Core
+}
+
+// Enabled 实现 zapcore.Core 接口，任一模块开启该级别时返回 true，具体在 Check 中判断
+func (c *levelFilterCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= logLevels.Load().min && c.Core.Enabled(lvl)
+}
+
+// With 实现 zapcore.Core 接口，添加字段
+func (c *levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelFilterCore{Core: c.Core.With(fields)}
+}
+
+// Check 实现 zapcore.Core 接口，按日志所属模块的级别判断是否输出
+func (c *levelFilterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < logLevels.Load().level(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// logLevelRequest 运维接口调整日志级别的参数
+type logLevelRequest struct {
+	Module string `form:"module" json:"module"`
+	Level  string `form:"level" json:"level" binding:"required"`
+	TTL    string `form:"ttl" json:"ttl"`
+}
+
+// logLevelResponse 运维接口返回的日志级别
+type logLevelResponse struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// logLevelHandler 运维接口：查看全局及模块的日志级别
+func logLevelHandler(c *gin.Context) {
+	global, modules := LogLevels()
+	rsp := &logLevelResponse{Level: global.String(), Modules: make(map[string]string, len(modules))}
+	for module, l := range modules {
+		rsp.Modules[module] = l.String()
+	}
+	JsonSuccess(c, rsp)
+}
+
+// setLogLevelHandler 运维接口：调整日志级别，如 PUT /log/level?module=payment&level=debug&ttl=10m
+func setLogLevelHandler(c *gin.Context) {
+	req := new(logLevelRequest)
+	if err := c.ShouldBind(req); err != nil {
+		JsonError(c, ErrParam, err.Error())
+		return
+	}
+	var level zapcore.Level
+	if err := level.Set(req.Level); err != nil {
+		JsonError(c, ErrParam, err.Error())
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.TTL); err != nil {
+			JsonError(c, ErrParam, err.Error())
+			return
+		}
+	}
+	SetLogLevel(req.Module, level, ttl)
+	Infotf(c, "log level of %s set to %s by admin, ttl %s", logModuleName(req.Module), level, ttl)
+	logLevelHandler(c)
+}
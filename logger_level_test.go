@@ -0,0 +1,181 @@
+package gohera
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// resetLogLevels 恢复默认的日志级别，测试结束后恢复原有的级别及临时调整
+func resetLogLevels(t *testing.T) {
+	logLevelMu.Lock()
+	saved, savedBase, savedTimers := logLevels.Load(), logLevelBase, logLevelTimers
+	logLevelBase = logLevelState{global: zapcore.DebugLevel, modules: map[string]zapcore.Level{}}
+	logLevelTimers = map[string]*time.Timer{}
+	storeLogLevels(zapcore.DebugLevel, map[string]zapcore.Level{})
+	logLevelMu.Unlock()
+	t.Cleanup(func() {
+		logLevelMu.Lock()
+		defer logLevelMu.Unlock()
+		for _, timer := range logLevelTimers {
+			timer.Stop()
+		}
+		logLevels.Store(saved)
+		logLevelBase, logLevelTimers = savedBase, savedTimers
+	})
+}
+
+// waitLogLevel 等待模块的日志级别变为 want
+func waitLogLevel(t *testing.T, module string, want zapcore.Level) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for GetLogLevel(module) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("level of %q = %s, want %s", module, GetLogLevel(module), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSetLogLevelTTL(t *testing.T) {
+	resetLogLevels(t)
+	if err := applyLogLevelConfig(loggerConfig{Level: "info", Modules: map[string]string{"mysql": "warn"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	SetLogLevel("payment", zapcore.DebugLevel, 20*time.Millisecond)
+	SetLogLevel("mysql", zapcore.ErrorLevel, 20*time.Millisecond)
+	SetLogLevel("", zapcore.WarnLevel, 20*time.Millisecond)
+	if GetLogLevel("payment") != zapcore.DebugLevel || GetLogLevel("mysql") != zapcore.ErrorLevel || GetLogLevel("") != zapcore.WarnLevel {
+		t.Fatal("SetLogLevel() did not take effect")
+	}
+	// 未单独设置的模块使用全局级别
+	if GetLogLevel("redis") != zapcore.WarnLevel {
+		t.Fatalf("level of redis = %s, want warn", GetLogLevel("redis"))
+	}
+
+	// 到期后恢复为配置文件中的级别
+	waitLogLevel(t, "payment", zapcore.InfoLevel)
+	waitLogLevel(t, "mysql", zapcore.WarnLevel)
+	waitLogLevel(t, "", zapcore.InfoLevel)
+	if _, modules := LogLevels(); len(modules) != 1 {
+		t.Fatalf("modules = %v, want only mysql", modules)
+	}
+}
+
+func TestSetLogLevelStaleTimer(t *testing.T) {
+	resetLogLevels(t)
+	if err := applyLogLevelConfig(loggerConfig{Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+	SetLogLevel("payment", zapcore.DebugLevel, time.Millisecond)
+
+	// 定时器已触发并等待锁时再次调整，已触发的定时器不能恢复新的调整
+	logLevelMu.Lock()
+	time.Sleep(20 * time.Millisecond)
+	setLogLevel("payment", zapcore.WarnLevel, time.Hour)
+	logLevelMu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+
+	logLevelMu.Lock()
+	_, ok := logLevelTimers["payment"]
+	logLevelMu.Unlock()
+	if GetLogLevel("payment") != zapcore.WarnLevel || !ok {
+		t.Fatalf("level of payment = %s, timer = %v, want warn with timer", GetLogLevel("payment"), ok)
+	}
+}
+
+func TestApplyLogLevelConfigKeepsOverrides(t *testing.T) {
+	resetLogLevels(t)
+	if err := applyLogLevelConfig(loggerConfig{Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+	SetLogLevel("payment", zapcore.DebugLevel, 50*time.Millisecond)
+	SetLogLevel("order", zapcore.DebugLevel, 0)
+
+	if err := applyLogLevelConfig(loggerConfig{Level: "warn", Modules: map[string]string{"payment": "error"}}); err != nil {
+		t.Fatal(err)
+	}
+	// 未到期的临时调整保持不变，不带 ttl 的调整被配置覆盖
+	if GetLogLevel("payment") != zapcore.DebugLevel {
+		t.Fatalf("level of payment = %s, want debug until ttl expires", GetLogLevel("payment"))
+	}
+	if GetLogLevel("order") != zapcore.WarnLevel || GetLogLevel("") != zapcore.WarnLevel {
+		t.Fatalf("level of order = %s, global = %s, want warn", GetLogLevel("order"), GetLogLevel(""))
+	}
+	// 到期后恢复为新的配置
+	waitLogLevel(t, "payment", zapcore.ErrorLevel)
+
+	if err := applyLogLevelConfig(loggerConfig{Level: "verbose"}); err == nil {
+		t.Fatal("applyLogLevelConfig() should fail on invalid level")
+	}
+}
+
+func TestWatchLogLevelConfig(t *testing.T) {
+	resetLogLevels(t)
+	resetConfigListeners(t)
+	dir := loadTestConfig(t, "", map[string]string{"app.toml": "[log]\nlevel = \"info\"\n"})
+	cfg, err := loadLoggerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = applyLogLevelConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	watchLogLevelConfig()
+	SetLogLevel("", zapcore.DebugLevel, time.Hour)
+
+	// [log] 中其他配置项的变更不影响日志级别
+	rewriteTestConfig(t, dir, "app.toml", "[log]\nlevel = \"info\"\ncompress = true\n")
+	if GetLogLevel("") != zapcore.DebugLevel {
+		t.Fatalf("global level = %s, want debug", GetLogLevel(""))
+	}
+
+	rewriteTestConfig(t, dir, "app.toml", "[log]\nlevel = \"info\"\n[log.modules]\nmysql = \"error\"\n")
+	if GetLogLevel("mysql") != zapcore.ErrorLevel {
+		t.Fatalf("level of mysql = %s, want error", GetLogLevel("mysql"))
+	}
+	if GetLogLevel("") != zapcore.DebugLevel {
+		t.Fatalf("global level = %s, want debug until ttl expires", GetLogLevel(""))
+	}
+
+	// 到期后恢复为配置文件中的级别
+	logLevelMu.Lock()
+	revertLogLevel("")
+	logLevelMu.Unlock()
+	if GetLogLevel("") != zapcore.InfoLevel {
+		t.Fatalf("global level = %s, want info", GetLogLevel(""))
+	}
+}
+
+func TestLevelFilterCore(t *testing.T) {
+	resetLogLevels(t)
+	if err := applyLogLevelConfig(loggerConfig{Level: "warn", Modules: map[string]string{"payment": "debug"}}); err != nil {
+		t.Fatal(err)
+	}
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(&levelFilterCore{Core: core})
+
+	log.Info("global info")
+	log.Warn("global warn")
+	log.Named("payment").Debug("payment debug")
+	log.Named("mysql").Info("mysql info")
+	log.With(zap.String("k", "v")).Named("payment").Info("payment info")
+
+	var got []string
+	for _, e := range logs.All() {
+		got = append(got, e.Message)
+	}
+	want := []string{"global warn", "payment debug", "payment info"}
+	if len(got) != len(want) {
+		t.Fatalf("logged %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("logged %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package gohera
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// samplingConfig [log.sampling] 配置
+type samplingConfig struct {
+	Enable        bool                    `mapstructure:"enable"`
+	Interval      time.Duration           `mapstructure:"interval" binding:"gte=0"`   // 采样计数的周期
+	First         int                     `mapstructure:"first" binding:"gte=0"`      // 每个周期内相同日志完整输出的条数
+	Thereafter    int                     `mapstructure:"thereafter" binding:"gte=0"` // 超过 first 后每 thereafter 条输出一条
+	Levels        map[string]samplingRule `mapstructure:"levels"`                     // 按级别单独设置 first/thereafter
+	Dedup         bool                    `mapstructure:"dedup"`                      // 合并重复日志
+	DedupInterval time.Duration           `mapstructure:"dedup_interval" binding:"gte=0"`
+}
+
+// samplingRule 采样规则
+type samplingRule struct {
+	First      int `mapstructure:"first" binding:"gte=0"`
+	Thereafter int `mapstructure:"thereafter" binding:"gte=0"`
+}
+
+// defaultSamplingConfig 默认采样配置
+func defaultSamplingConfig() samplingConfig {
+	return samplingConfig{
+		Interval:      time.Second,
+		First:         100,
+		Thereafter:    100,
+		DedupInterval: 10 * time.Second,
+	}
+}
+
+// dedupSampleTraces 重复日志汇总中保留的 trace_id 个数
+const dedupSampleTraces = 5
+
+// sampler 日志采样及重复日志合并
+type sampler struct {
+	cfg   samplingConfig
+	rules map[zapcore.Level]samplingRule
+
+	mu      sync.Mutex
+	counts  map[string]int
+	resetAt time.Time
+	dedups  map[string]*dedupEntry
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// dedupEntry 周期内重复的日志
+type dedupEntry struct {
+	core    zapcore.Core
+	ent     zapcore.Entry
+	start   time.Time
+	count   int
+	traceId []string
+}
+
+var logSampler atomic.Pointer[sampler]
+
+// newSampler 根据配置创建采样器，未开启采样及合并时返回 nil
+func newSampler(cfg samplingConfig) (*sampler, error) {
+	if !cfg.Enable && !cfg.Dedup {
+		return nil, nil
+	}
+	def := defaultSamplingConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.DedupInterval <= 0 {
+		cfg.DedupInterval = def.DedupInterval
+	}
+	s := &sampler{
+		cfg:    cfg,
+		rules:  make(map[zapcore.Level]samplingRule),
+		counts: make(map[string]int),
+		dedups: make(map[string]*dedupEntry),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for name, rule := range cfg.Levels {
+		var l zapcore.Level
+		if err := l.Set(name); err != nil {
+			return nil, fmt.Errorf("log.sampling.levels.%s: %w", name, err)
+		}
+		s.rules[l] = rule
+	}
+	if cfg.Dedup {
+		go s.run()
+	}
+	return s, nil
+}
+
+// applySamplingConfig 应用采样配置，替换时输出原采样器中尚未输出的重复日志汇总
+func applySamplingConfig(cfg samplingConfig) error {
+	s, err := newSampler(cfg)
+	if err != nil {
+		return err
+	}
+	if old := logSampler.Swap(s); old != nil {
+		old.close()
+	}
+	return nil
+}
+
+// watchSamplingConfig 配置热更新时重新应用采样配置
+func watchSamplingConfig() {
+	OnChange("log.sampling", func(_, _ any) {
+		cfg, err := loadLoggerConfig()
+		if err == nil {
+			err = applySamplingConfig(cfg.Sampling)
+		}
+		if err != nil {
+			Errortf(context.Background(), "log sampling config fail: %v", err)
+		}
+	})
+}
+
+// rule 获取级别的采样规则
+func (s *sampler) rule(lvl zapcore.Level) samplingRule {
+	if r, ok := s.rules[lvl]; ok {
+		return r
+	}
+	return samplingRule{First: s.cfg.First, Thereafter: s.cfg.Thereafter}
+}
+
+// sample 判断日志是否输出
+// 超过 first 后，带有 trace_id 的日志按 trace_id 的哈希采样，同一请求的日志要么全部输出要么全部丢弃，
+// 不同请求被均匀采样，不会因为某个请求日志较多而挤占其他请求
+func (s *sampler) sample(ent zapcore.Entry, traceId string) bool {
+	if !s.cfg.Enable {
+		return true
+	}
+	rule := s.rule(ent.Level)
+	key := ent.Level.String() + "|" + ent.Message
+
+	s.mu.Lock()
+	if ent.Time.After(s.resetAt) {
+		s.counts = make(map[string]int)
+		s.resetAt = ent.Time.Add(s.cfg.Interval)
+	}
+	s.counts[key]++
+	n := s.counts[key]
+	s.mu.Unlock()
+
+	if n <= rule.First {
+		return true
+	}
+	if rule.Thereafter <= 0 {
+		return false
+	}
+	if traceId != "" {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(traceId))
+		return h.Sum32()%uint32(rule.Thereafter) == 0
+	}
+	return (n-rule.First)%rule.Thereafter == 0
+}
+
+// dedup 判断日志是否为周期内的重复日志，重复时计数并返回 true
+func (s *sampler) dedup(core zapcore.Core, ent zapcore.Entry, traceId string) bool {
+	if !s.cfg.Dedup {
+		return false
+	}
+	key := ent.LoggerName + "|" + ent.Level.String() + "|" + ent.Message
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.dedups[key]
+	if !ok {
+		s.dedups[key] = &dedupEntry{core: core, ent: ent, start: ent.Time}
+		return false
+	}
+	e.count++
+	if traceId != "" && len(e.traceId) < dedupSampleTraces {
+		e.traceId = append(e.traceId, traceId)
+	}
+	return true
+}
+
+// run 定时输出重复日志的汇总
+func (s *sampler) run() {
+	ticker := time.NewTicker(s.cfg.DedupInterval / 2)
+	defer ticker.Stop()
+	defer close(s.done)
+	for {
+		select {
+		case <-s.stop:
+			s.flush(time.Time{})
+			return
+		case now := <-ticker.C:
+			s.flush(now)
+		}
+	}
+}
+
+// close 停止采样器，等待所有重复日志的汇总输出完成
+func (s *sampler) close() {
+	if s != nil && s.cfg.Dedup {
+		close(s.stop)
+		<-s.done
+	}
+}
+
+// flush 输出周期已结束的重复日志汇总，now 为零值时输出全部
+func (s *sampler) flush(now time.Time) {
+	s.mu.Lock()
+	var expired []*dedupEntry
+	for key, e := range s.dedups {
+		if now.IsZero() || now.Sub(e.start) >= s.cfg.DedupInterval {
+			delete(s.dedups, key)
+			if e.count > 0 {
+				expired = append(expired, e)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	for _, e := range expired {
+		ent := e.ent
+		ent.Time = time.Now()
+		ent.Message = fmt.Sprintf("%s (repeated %d times)", e.ent.Message, e.count)
+		if ce := e.core.Check(ent, nil); ce != nil {
+			ce.Write(zap.Int("x_repeated", e.count), zap.Strings("x_trace_ids", e.traceId))
+		}
+	}
+}
+
+// samplingCore 按采样配置过滤日志
+// 采样需要使用字段中的 trace_id，因此在 Write 中判断，通过后再由被包装的 Core 按级别写入
+type samplingCore struct {
+	zapcore.Core
+}
+
+// With 实现 zapcore.Core 接口，添加字段
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplingCore{Core: c.Core.With(fields)}
+}
+
+// Check 实现 zapcore.Core 接口
+func (c *samplingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if logSampler.Load() == nil {
+		return c.Core.Check(ent, ce)
+	}
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 实现 zapcore.Core 接口
+func (c *samplingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	// Fatal 等高于 Error 的日志不参与采样
+	if s := logSampler.Load(); s != nil && ent.Level <= zapcore.ErrorLevel {
+		traceId := requestTraceId(fields)
+		if s.dedup(c.Core, ent, traceId) || !s.sample(ent, traceId) {
+			return nil
+		}
+	}
+	if ce := c.Core.Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+// requestTraceId 获取请求的 trace_id，没有 Trace 信息时生成的 x_trace_id 返回空字符串
+func requestTraceId(fields []zapcore.Field) string {
+	for _, f := range fields {
+		if f.Key == "x_trace_id" && f.Type == zapcore.StringType {
+			if _, ok := f.Interface.(generatedTraceId); ok {
+				return ""
+			}
+			return f.String
+		}
+	}
+	return ""
+}
+
+// fieldString 获取字符串字段的值
+func fieldString(fields []zapcore.Field, key string) string {
+	for _, f := range fields {
+		if f.Key == key && f.Type == zapcore.StringType {
+			return f.String
+		}
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package gohera
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observeSampledLogs 使用 cfg 采样，日志输出到内存中的 observer，测试结束后恢复
+func observeSampledLogs(t *testing.T, cfg samplingConfig) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	saved := logger
+	logger = zap.New(&samplingCore{Core: core}).WithOptions(zap.AddCallerSkip(2))
+	if err := applySamplingConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		logSampler.Swap(nil).close()
+		logger = saved
+	})
+	return logs
+}
+
+func TestSamplingWithoutTrace(t *testing.T) {
+	logs := observeSampledLogs(t, samplingConfig{Enable: true, Interval: time.Hour, First: 2, Thereafter: 3})
+	for i := 0; i < 11; i++ {
+		InfoFields(context.Background(), "no trace", zap.Int("i", i))
+	}
+	// 没有 Trace 信息时不按随机生成的 x_trace_id 采样，前 2 条之后每 3 条输出一条
+	var kept []string
+	for _, e := range logs.All() {
+		kept = append(kept, fmt.Sprint(e.ContextMap()["i"]))
+	}
+	if got := strings.Join(kept, ","); got != "0,1,4,7,10" {
+		t.Fatalf("kept entries %s, want 0,1,4,7,10", got)
+	}
+	// 输出的日志仍带有随机生成的 x_trace_id
+	if id := logs.All()[0].ContextMap()["x_trace_id"]; id == "" {
+		t.Fatal("x_trace_id is empty")
+	}
+}
+
+func TestSamplingByTrace(t *testing.T) {
+	logs := observeSampledLogs(t, samplingConfig{Enable: true, Interval: time.Hour, First: 1, Thereafter: 4})
+	kept := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		traceId := fmt.Sprintf("trace-%d", i)
+		ctx := context.WithValue(context.Background(), TraceCtx, &Trace{TraceId: traceId})
+		for j := 0; j < 3; j++ {
+			Info(ctx, "in request")
+		}
+	}
+	for _, e := range logs.All() {
+		kept[e.ContextMap()["x_trace_id"].(string)]++
+	}
+	// 同一请求的日志要么全部输出要么全部丢弃，first 内的第一条除外
+	for traceId, n := range kept {
+		if n != 3 && traceId != "trace-0" {
+			t.Fatalf("trace %s kept %d entries, want 3", traceId, n)
+		}
+	}
+	if len(kept) < 20 || len(kept) > 80 {
+		t.Fatalf("kept %d of 200 traces, want about 50", len(kept))
+	}
+}
+
+func TestSamplingLevelsAndFatal(t *testing.T) {
+	s, err := newSampler(samplingConfig{Enable: true, Interval: time.Hour, First: 1, Thereafter: 0, Levels: map[string]samplingRule{"error": {First: 3}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	var info, errs int
+	for i := 0; i < 5; i++ {
+		if s.sample(zapcore.Entry{Level: zapcore.InfoLevel, Message: "m", Time: now}, "") {
+			info++
+		}
+		if s.sample(zapcore.Entry{Level: zapcore.ErrorLevel, Message: "m", Time: now}, "") {
+			errs++
+		}
+	}
+	if info != 1 || errs != 3 {
+		t.Fatalf("sampled info = %d, error = %d, want 1 and 3", info, errs)
+	}
+	// 新的周期重新计数
+	if !s.sample(zapcore.Entry{Level: zapcore.InfoLevel, Message: "m", Time: now.Add(2 * time.Hour)}, "") {
+		t.Fatal("counts should be reset in a new interval")
+	}
+
+	if _, err = newSampler(samplingConfig{Enable: true, Levels: map[string]samplingRule{"verbose": {}}}); err == nil {
+		t.Fatal("newSampler() should fail on invalid level")
+	}
+}
+
+func TestSamplingDedup(t *testing.T) {
+	logs := observeSampledLogs(t, samplingConfig{Dedup: true, DedupInterval: time.Hour})
+	for i := 0; i < 4; i++ {
+		ctx := context.WithValue(context.Background(), TraceCtx, &Trace{TraceId: fmt.Sprintf("trace-%d", i)})
+		Warn(ctx, "db timeout")
+	}
+	Warn(context.Background(), "db timeout")
+	if n := logs.Len(); n != 1 {
+		t.Fatalf("logged %d entries before flush, want 1", n)
+	}
+
+	// 替换采样器时输出尚未输出的汇总
+	if err := applySamplingConfig(samplingConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	entries := logs.All()
+	if len(entries) != 2 || !strings.HasSuffix(entries[1].Message, "(repeated 4 times)") {
+		t.Fatalf("entries = %v, want repeated summary", entries)
+	}
+	m := entries[1].ContextMap()
+	ids, _ := m["x_trace_ids"].([]any)
+	// 生成的 x_trace_id 不计入汇总
+	if m["x_repeated"] != int64(4) || len(ids) != 3 {
+		t.Fatalf("summary fields = %v", m)
+	}
+}
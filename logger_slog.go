@@ -0,0 +1,235 @@
+package gohera
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogHandler 将 log/slog 的日志写入框架的日志文件
+type slogHandler struct {
+	module *ModuleLogger
+	fields []zap.Field
+}
+
+// NewSlogHandler 创建写入框架日志的 slog.Handler
+// 日志与 Info/Error 等函数输出到相同的文件，并从 slog.InfoContext 等传入的 Context 中提取 Trace 信息
+func NewSlogHandler() slog.Handler {
+	return &slogHandler{module: rootLogger}
+}
+
+// SlogHandler 创建写入模块日志的 slog.Handler，日志级别使用模块的级别
+func (m *ModuleLogger) SlogHandler() slog.Handler {
+	return &slogHandler{module: m}
+}
+
+// zapLogger 获取当前的 zap 日志记录器，日志初始化后 logger 会被替换，因此每次使用时获取
+func (h *slogHandler) zapLogger() *zap.Logger {
+	if h.module.name != "" {
+		return logger.Named(h.module.name)
+	}
+	return logger
+}
+
+// Enabled 实现 slog.Handler 接口
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	lvl := slogToZapLevel(level)
+	return lvl >= GetLogLevel(h.module.name) && h.zapLogger().Core().Enabled(lvl)
+}
+
+// Handle 实现 slog.Handler 接口
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ce := h.zapLogger().Check(slogToZapLevel(r.Level), r.Message)
+	if ce == nil {
+		return nil
+	}
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+	// 调用层级经过 log/slog 内部，使用 Record 中记录的调用方
+	if r.PC != 0 && ce.Caller.Defined {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+		ce.Caller.Function = frame.Function
+	}
+	fields := getContextFields(ctx)
+	fields = append(fields, h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendSlogAttr(fields, a)
+		return true
+	})
+	ce.Write(fields...)
+	return nil
+}
+
+// WithAttrs 实现 slog.Handler 接口
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]zap.Field, len(h.fields), len(h.fields)+len(attrs))
+	copy(fields, h.fields)
+	for _, a := range attrs {
+		fields = appendSlogAttr(fields, a)
+	}
+	return &slogHandler{module: h.module, fields: fields}
+}
+
+// WithGroup 实现 slog.Handler 接口，之后的字段嵌套在 name 对象中
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	fields := make([]zap.Field, len(h.fields), len(h.fields)+1)
+	copy(fields, h.fields)
+	return &slogHandler{module: h.module, fields: append(fields, zap.Namespace(name))}
+}
+
+// appendSlogAttr 将 slog.Attr 转换为 zap 字段，分组转换为嵌套对象，key 为空的分组展开到当前层级
+func appendSlogAttr(fields []zap.Field, a slog.Attr) []zap.Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key == "" {
+			for _, ga := range attrs {
+				fields = appendSlogAttr(fields, ga)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(a.Key, slogGroup(attrs)))
+	}
+	return append(fields, slogAttrField(a))
+}
+
+// slogAttrField 转换单个非分组的 slog.Attr
+func slogAttrField(a slog.Attr) zap.Field {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return zap.String(a.Key, a.Value.String())
+	case slog.KindInt64:
+		return zap.Int64(a.Key, a.Value.Int64())
+	case slog.KindUint64:
+		return zap.Uint64(a.Key, a.Value.Uint64())
+	case slog.KindFloat64:
+		return zap.Float64(a.Key, a.Value.Float64())
+	case slog.KindBool:
+		return zap.Bool(a.Key, a.Value.Bool())
+	case slog.KindDuration:
+		return zap.Duration(a.Key, a.Value.Duration())
+	case slog.KindTime:
+		return zap.Time(a.Key, a.Value.Time())
+	default:
+		return zap.Any(a.Key, a.Value.Any())
+	}
+}
+
+// slogGroup slog 分组的 zap 对象编码
+type slogGroup []slog.Attr
+
+// MarshalLogObject 实现 zapcore.ObjectMarshaler 接口
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []zap.Field
+	for _, a := range g {
+		fields = appendSlogAttr(fields, a)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return nil
+}
+
+// slogToZapLevel 将 slog 级别转换为 zap 级别，介于两个级别之间的取较低的级别
+func slogToZapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case level >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
+
+// zapToSlogLevel 将 zap 级别转换为 slog 级别，Fatal 等高于 Error 的级别按 Error+4 递增
+func zapToSlogLevel(level zapcore.Level) slog.Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return slog.LevelDebug
+	case level == zapcore.InfoLevel:
+		return slog.LevelInfo
+	case level == zapcore.WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError + slog.Level(level-zapcore.ErrorLevel)*4
+	}
+}
+
+// slogOutput 包级别日志函数的输出目标，为空时写入框架的日志文件
+var slogOutput atomic.Pointer[slog.Handler]
+
+// SetSlogOutput 将 Info/Error 等日志函数的输出转发到 h，Trace 信息及结构化字段转换为 slog.Attr
+// h 为 nil 或 NewSlogHandler 创建的 Handler 时恢复写入框架的日志文件
+func SetSlogOutput(h slog.Handler) {
+	if _, ok := h.(*slogHandler); ok || h == nil {
+		slogOutput.Store(nil)
+		return
+	}
+	slogOutput.Store(&h)
+}
+
+// logSlog 将脱敏后的日志转发到 slog.Handler，模块名作为 x_logger 字段输出
+func (m *ModuleLogger) logSlog(h slog.Handler, ctx context.Context, lvl zapcore.Level, template string, args []any, fields []zap.Field) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	level := zapToSlogLevel(lvl)
+	if lvl < GetLogLevel(m.name) || !h.Enabled(ctx, level) {
+		if lvl == zapcore.FatalLevel {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 跳过 runtime.Callers、logSlog、log 及日志函数，记录调用方的位置
+	var pcs [1]uintptr
+	runtime.Callers(4, pcs[:])
+	msg, ctxFields := StartSpan(ctx, template, args...)
+	fields = append(ctxFields, fields...)
+	// 与写入日志文件时一致，转发前对日志内容及字段脱敏
+	if mk := masker.Load(); !mk.disable {
+		msg = mk.maskString(msg)
+		fields = mk.maskFields(fields)
+	}
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+
+	if m.name != "" {
+		r.AddAttrs(slog.String("x_logger", m.name))
+	}
+	// 先编码为 map 再按字段顺序转换，保证输出顺序与写入日志文件时一致
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if v, ok := enc.Fields[f.Key]; ok && !seen[f.Key] {
+			seen[f.Key] = true
+			r.AddAttrs(slog.Any(f.Key, v))
+		}
+	}
+	_ = h.Handle(ctx, r)
+	if lvl == zapcore.FatalLevel {
+		os.Exit(1)
+	}
+}
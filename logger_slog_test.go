@@ -0,0 +1,167 @@
+package gohera
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSlogHandler(t *testing.T) {
+	logs := observeLogs(t)
+	log := slog.New(NewSlogHandler()).With("service", "order")
+	log.InfoContext(testTraceContext(), "order paid",
+		"order_id", "A1",
+		"amount", 100,
+		"cost", time.Second,
+		slog.Group("user", "id", 42, "vip", true),
+		slog.Group("", "inline", "yes"),
+		slog.Group("empty"),
+	)
+	log.WithGroup("req").Warn("slow", "path", "/orders")
+	log.Debug("debug", "k", "v")
+	log.Log(context.Background(), slog.LevelError+4, "critical")
+
+	entries := logs.TakeAll()
+	if len(entries) != 4 {
+		t.Fatalf("logged %d entries, want 4", len(entries))
+	}
+	m := entries[0].ContextMap()
+	if entries[0].Message != "order paid" || entries[0].Level != zapcore.InfoLevel {
+		t.Fatalf("entry = %s %q", entries[0].Level, entries[0].Message)
+	}
+	if m["x_trace_id"] != "trace-1" || m["service"] != "order" || m["order_id"] != "A1" || m["amount"] != int64(100) || m["cost"] != time.Second || m["inline"] != "yes" {
+		t.Fatalf("fields = %v", m)
+	}
+	if user, ok := m["user"].(map[string]any); !ok || user["id"] != int64(42) || user["vip"] != true {
+		t.Fatalf("user = %v", m["user"])
+	}
+	if _, ok := m["empty"]; ok {
+		t.Fatal("empty group should be omitted")
+	}
+	if req, ok := entries[1].ContextMap()["req"].(map[string]any); !ok || req["path"] != "/orders" {
+		t.Fatalf("req = %v", entries[1].ContextMap()["req"])
+	}
+	if entries[2].Level != zapcore.DebugLevel || entries[3].Level != zapcore.ErrorLevel {
+		t.Fatalf("levels = %s %s", entries[2].Level, entries[3].Level)
+	}
+}
+
+func TestSlogHandlerCaller(t *testing.T) {
+	logs := observeLogs(t)
+	slog.New(NewSlogHandler()).Info("caller")
+	slog.New(NewSlogHandler()).With("k", "v").WarnContext(context.Background(), "caller")
+
+	entries := logs.TakeAll()
+	if len(entries) != 2 {
+		t.Fatalf("logged %d entries, want 2", len(entries))
+	}
+	for _, e := range entries {
+		if filepath.Base(e.Caller.File) != "logger_slog_test.go" || !strings.HasSuffix(e.Caller.Function, "TestSlogHandlerCaller") {
+			t.Errorf("caller = %s %s", e.Caller.String(), e.Caller.Function)
+		}
+	}
+}
+
+func TestSlogHandlerModuleLevel(t *testing.T) {
+	resetLogLevels(t)
+	observeLogs(t)
+	SetLogLevel("payment", zapcore.WarnLevel, 0)
+
+	h := Named("payment").SlogHandler()
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Fatal("info should be disabled for payment")
+	}
+	if !h.Enabled(context.Background(), slog.LevelWarn) || !NewSlogHandler().Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("warn should be enabled for payment and debug for the root logger")
+	}
+}
+
+func TestSlogLevelConversion(t *testing.T) {
+	for _, tt := range []struct {
+		slog slog.Level
+		zap  zapcore.Level
+	}{
+		{slog.LevelDebug - 4, zapcore.DebugLevel},
+		{slog.LevelDebug, zapcore.DebugLevel},
+		{slog.LevelInfo + 2, zapcore.InfoLevel},
+		{slog.LevelWarn, zapcore.WarnLevel},
+		{slog.LevelError, zapcore.ErrorLevel},
+		{slog.LevelError + 8, zapcore.ErrorLevel},
+	} {
+		if got := slogToZapLevel(tt.slog); got != tt.zap {
+			t.Errorf("slogToZapLevel(%s) = %s, want %s", tt.slog, got, tt.zap)
+		}
+	}
+	if got := zapToSlogLevel(zapcore.FatalLevel); got != slog.LevelError+12 {
+		t.Errorf("zapToSlogLevel(fatal) = %s", got)
+	}
+	if got := zapToSlogLevel(zapcore.WarnLevel); got != slog.LevelWarn {
+		t.Errorf("zapToSlogLevel(warn) = %s", got)
+	}
+}
+
+// setTestSlogOutput 将日志函数的输出转发到 JSON 格式的 slog.Handler，测试结束后恢复
+func setTestSlogOutput(t *testing.T, level slog.Level) *bytes.Buffer {
+	var buf bytes.Buffer
+	SetSlogOutput(slog.NewJSONHandler(&buf, &slog.HandlerOptions{AddSource: true, Level: level}))
+	t.Cleanup(func() {
+		SetSlogOutput(nil)
+	})
+	return &buf
+}
+
+// decodeSlogLines 解析 JSON 格式的 slog 输出
+func decodeSlogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	d := json.NewDecoder(buf)
+	for d.More() {
+		m := make(map[string]any)
+		if err := d.Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestSetSlogOutput(t *testing.T) {
+	resetLogLevels(t)
+	logs := observeLogs(t)
+	buf := setTestSlogOutput(t, slog.LevelInfo)
+
+	Infotf(testTraceContext(), "order %s paid", "A1")
+	Named("payment").Ctx(context.Background()).With("amount", 100).Warn("retry")
+	Debug(context.Background(), "dropped by handler level")
+
+	lines := decodeSlogLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("forwarded %d lines, want 2", len(lines))
+	}
+	if lines[0]["msg"] != "order A1 paid" || lines[0]["level"] != "INFO" || lines[0]["x_trace_id"] != "trace-1" {
+		t.Fatalf("line = %v", lines[0])
+	}
+	if src, _ := lines[0]["source"].(map[string]any); src == nil || filepath.Base(src["file"].(string)) != "logger_slog_test.go" {
+		t.Fatalf("source = %v, want logger_slog_test.go", lines[0]["source"])
+	}
+	if lines[1]["x_logger"] != "payment" || lines[1]["amount"] != float64(100) || lines[1]["level"] != "WARN" {
+		t.Fatalf("line = %v", lines[1])
+	}
+	if n := len(logs.All()); n != 0 {
+		t.Fatalf("logged %d entries to the zap logger, want 0", n)
+	}
+
+	// 传入 NewSlogHandler 创建的 Handler 时恢复写入日志文件，避免循环转发
+	SetSlogOutput(NewSlogHandler())
+	Info(context.Background(), "back to zap")
+	if n := len(logs.All()); n != 1 {
+		t.Fatalf("logged %d entries to the zap logger, want 1", n)
+	}
+}
@@ -0,0 +1,133 @@
+package gohera
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observeLogs 将日志输出替换为内存中的 observer，测试结束后恢复
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	saved := logger
+	logger = zap.New(core, zap.AddCaller()).WithOptions(zap.AddCallerSkip(2))
+	t.Cleanup(func() {
+		logger = saved
+	})
+	return logs
+}
+
+// testTraceContext 携带 Trace 信息的 Context
+func testTraceContext() context.Context {
+	return context.WithValue(context.Background(), TraceCtx, &Trace{TraceId: "trace-1", SpanId: "1.1", UserId: "42", Path: "/orders"})
+}
+
+func TestInfoFields(t *testing.T) {
+	logs := observeLogs(t)
+	InfoFields(testTraceContext(), "order paid", zap.String("order_id", "A1"), zap.Int("amount", 100))
+
+	entries := logs.TakeAll()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Message != "order paid" || e.Level != zapcore.InfoLevel {
+		t.Fatalf("entry = %s %q", e.Level, e.Message)
+	}
+	// 结构化字段追加在 Trace 信息之后
+	var keys []string
+	for _, f := range e.Context {
+		keys = append(keys, f.Key)
+	}
+	want := []string{"x_trace_id", "x_span_id", "x_user_id", "x_path", "x_status", "x_header", "order_id", "amount"}
+	if len(keys) != len(want) {
+		t.Fatalf("fields = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("fields = %v, want %v", keys, want)
+		}
+	}
+	m := e.ContextMap()
+	if m["x_trace_id"] != "trace-1" || m["x_user_id"] != "42" || m["order_id"] != "A1" || m["amount"] != int64(100) {
+		t.Fatalf("fields = %v", m)
+	}
+	if filepath.Base(e.Caller.File) != "logger_test.go" {
+		t.Fatalf("caller = %s, want logger_test.go", e.Caller.File)
+	}
+}
+
+func TestFieldsLevels(t *testing.T) {
+	logs := observeLogs(t)
+	ctx := context.Background()
+	DebugFields(ctx, "d")
+	WarnFields(ctx, "w")
+	ErrorFields(ctx, "e", zap.Error(errors.New("boom")))
+	Named("payment").InfoFields(ctx, "i")
+
+	entries := logs.TakeAll()
+	levels := []zapcore.Level{zapcore.DebugLevel, zapcore.WarnLevel, zapcore.ErrorLevel, zapcore.InfoLevel}
+	if len(entries) != len(levels) {
+		t.Fatalf("logged %d entries, want %d", len(entries), len(levels))
+	}
+	for i, l := range levels {
+		if entries[i].Level != l {
+			t.Errorf("entries[%d].Level = %s, want %s", i, entries[i].Level, l)
+		}
+	}
+	if entries[2].ContextMap()["error"] != "boom" {
+		t.Errorf("error field = %v", entries[2].ContextMap()["error"])
+	}
+	if entries[3].LoggerName != "payment" {
+		t.Errorf("logger name = %q, want payment", entries[3].LoggerName)
+	}
+}
+
+func TestContextLoggerWith(t *testing.T) {
+	logs := observeLogs(t)
+	base := Ctx(testTraceContext()).With("order_id", "A1")
+	child := base.With(zap.Int("amount", 100), "retry", true, "dangling")
+	child.Infotf("order %s paid", "A1")
+	base.Warn("base")
+	Named("payment").Ctx(context.Background()).With("k", "v").Error("module")
+
+	entries := logs.TakeAll()
+	if len(entries) != 3 {
+		t.Fatalf("logged %d entries, want 3", len(entries))
+	}
+	m := entries[0].ContextMap()
+	if entries[0].Message != "order A1 paid" || m["order_id"] != "A1" || m["amount"] != int64(100) || m["retry"] != true {
+		t.Fatalf("child entry = %q %v", entries[0].Message, m)
+	}
+	// 缺少 value 的 key 原样保留
+	if m["x_ignored"] != "dangling" {
+		t.Fatalf("x_ignored = %v, want dangling", m["x_ignored"])
+	}
+	// With 不修改原有的日志记录器
+	m = entries[1].ContextMap()
+	if _, ok := m["amount"]; ok || m["order_id"] != "A1" || m["x_trace_id"] != "trace-1" {
+		t.Fatalf("base entry = %v", m)
+	}
+	if entries[2].LoggerName != "payment" || entries[2].ContextMap()["k"] != "v" {
+		t.Fatalf("module entry = %q %v", entries[2].LoggerName, entries[2].ContextMap())
+	}
+}
+
+func TestLoggerLevelFilter(t *testing.T) {
+	resetLogLevels(t)
+	logs := observeLogs(t)
+	logger = zap.New(&levelFilterCore{Core: logger.Core()}).WithOptions(zap.AddCallerSkip(2))
+	SetLogLevel("", zapcore.WarnLevel, 0)
+	SetLogLevel("payment", zapcore.DebugLevel, 0)
+
+	InfoFields(context.Background(), "dropped")
+	Named("payment").DebugFields(context.Background(), "kept")
+	if entries := logs.TakeAll(); len(entries) != 1 || entries[0].Message != "kept" {
+		t.Fatalf("entries = %v, want only kept", entries)
+	}
+}
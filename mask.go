@@ -0,0 +1,473 @@
+package gohera
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maskConfig [log.mask] 配置
+type maskConfig struct {
+	Disable     bool     `mapstructure:"disable"`
+	HeaderAllow []string `mapstructure:"header_allow"` // 不为空时仅输出列表中的请求头
+	HeaderDeny  []string `mapstructure:"header_deny"`  // 值被脱敏的请求头
+	Keys        []string `mapstructure:"keys"`         // 字段名匹配任一正则时整体脱敏
+	Values      []string `mapstructure:"values"`       // 内置规则 phone/id_card/bank_card 或自定义正则
+}
+
+// defaultMaskConfig 默认脱敏配置
+func defaultMaskConfig() maskConfig {
+	return maskConfig{
+		HeaderDeny: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Auth-Token", "X-Access-Token"},
+		Keys:       []string{configRedactPattern.String()},
+		Values:     []string{"phone", "id_card", "bank_card"},
+	}
+}
+
+// maskRule 值脱敏规则，保留匹配内容的前 keepPrefix 位和后 keepSuffix 位
+type maskRule struct {
+	re         *regexp.Regexp
+	valid      func(string) bool
+	keepPrefix int
+	keepSuffix int
+}
+
+// builtinMaskRules 内置的值脱敏规则，身份证需要在银行卡之前匹配
+var builtinMaskRules = map[string]maskRule{
+	"phone":     {re: regexp.MustCompile(`\b1[3-9]\d{9}\b`), keepPrefix: 3, keepSuffix: 4},
+	"id_card":   {re: regexp.MustCompile(`\b[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`), keepPrefix: 3, keepSuffix: 4},
+	"bank_card": {re: regexp.MustCompile(`\b[1-9]\d{15,18}\b`), valid: luhnValid, keepPrefix: 4, keepSuffix: 4},
+}
+
+// Masker 日志脱敏器
+type Masker struct {
+	disable     bool
+	headerAllow map[string]bool
+	headerDeny  map[string]bool
+	keys        []*regexp.Regexp
+	values      []maskRule
+}
+
+var masker atomic.Pointer[Masker]
+
+func init() {
+	m, _ := newMasker(defaultMaskConfig())
+	masker.Store(m)
+}
+
+// newMasker 根据配置创建脱敏器
+func newMasker(cfg maskConfig) (*Masker, error) {
+	m := &Masker{
+		disable:     cfg.Disable,
+		headerAllow: make(map[string]bool, len(cfg.HeaderAllow)),
+		headerDeny:  make(map[string]bool, len(cfg.HeaderDeny)),
+	}
+	for _, h := range cfg.HeaderAllow {
+		m.headerAllow[http.CanonicalHeaderKey(h)] = true
+	}
+	for _, h := range cfg.HeaderDeny {
+		m.headerDeny[http.CanonicalHeaderKey(h)] = true
+	}
+	for _, k := range cfg.Keys {
+		re, err := regexp.Compile("(?i)" + k)
+		if err != nil {
+			return nil, fmt.Errorf("log.mask.keys %q: %w", k, err)
+		}
+		m.keys = append(m.keys, re)
+	}
+	// 内置规则按固定顺序排在自定义规则之前
+	for _, name := range []string{"id_card", "bank_card", "phone"} {
+		for _, v := range cfg.Values {
+			if v == name {
+				m.values = append(m.values, builtinMaskRules[name])
+			}
+		}
+	}
+	for _, v := range cfg.Values {
+		if _, ok := builtinMaskRules[v]; ok {
+			continue
+		}
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return nil, fmt.Errorf("log.mask.values %q: %w", v, err)
+		}
+		m.values = append(m.values, maskRule{re: re})
+	}
+	return m, nil
+}
+
+// applyMaskConfig 应用脱敏配置
+func applyMaskConfig(cfg maskConfig) error {
+	m, err := newMasker(cfg)
+	if err != nil {
+		return err
+	}
+	masker.Store(m)
+	return nil
+}
+
+// watchMaskConfig 配置热更新时重新应用脱敏配置，配置不合法时保留原配置
+func watchMaskConfig() {
+	OnChange("log.mask", func(_, _ any) {
+		cfg, err := loadLoggerConfig()
+		if err == nil {
+			err = applyMaskConfig(cfg.Mask)
+		}
+		if err != nil {
+			Errortf(context.Background(), "log mask config fail: %v", err)
+		}
+	})
+}
+
+// MaskString 对字符串中的手机号、身份证号、银行卡号等敏感内容脱敏
+func MaskString(s string) string {
+	return masker.Load().maskString(s)
+}
+
+// MaskValue 对字段值脱敏，字段名匹配敏感规则时整体脱敏，字符串按值规则脱敏
+func MaskValue(key string, value any) any {
+	return masker.Load().maskValue(key, value)
+}
+
+// MaskHeader 对请求头脱敏，返回脱敏后的副本
+// 配置了 header_allow 时仅保留列表中的请求头，header_deny 中的请求头值替换为 ******
+func MaskHeader(h http.Header) http.Header {
+	m := masker.Load()
+	masked := make(http.Header, len(h))
+	for k, vs := range h {
+		if m.disable {
+			masked[k] = vs
+			continue
+		}
+		switch m.headerValue(k) {
+		case headerDrop:
+		case headerRedact:
+			masked[k] = []string{RedactedValue}
+		default:
+			values := make([]string, len(vs))
+			for i, v := range vs {
+				values[i] = m.maskString(v)
+			}
+			masked[k] = values
+		}
+	}
+	return masked
+}
+
+const (
+	headerKeep = iota
+	headerRedact
+	headerDrop
+)
+
+// headerValue 判断请求头的处理方式
+func (m *Masker) headerValue(key string) int {
+	key = http.CanonicalHeaderKey(key)
+	if len(m.headerAllow) > 0 && !m.headerAllow[key] {
+		return headerDrop
+	}
+	if m.headerDeny[key] || m.matchKey(key) {
+		return headerRedact
+	}
+	return headerKeep
+}
+
+// matchKey 字段名是否匹配敏感规则
+func (m *Masker) matchKey(key string) bool {
+	for _, re := range m.keys {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskString 按值规则脱敏
+func (m *Masker) maskString(s string) string {
+	if m.disable {
+		return s
+	}
+	for _, r := range m.values {
+		s = r.re.ReplaceAllStringFunc(s, func(match string) string {
+			if r.valid != nil && !r.valid(match) {
+				return match
+			}
+			if r.keepPrefix+r.keepSuffix >= len(match) {
+				return RedactedValue
+			}
+			return match[:r.keepPrefix] + strings.Repeat("*", len(match)-r.keepPrefix-r.keepSuffix) + match[len(match)-r.keepSuffix:]
+		})
+	}
+	return s
+}
+
+// maskValue 对字段值脱敏，map 中的值按字段名递归脱敏
+func (m *Masker) maskValue(key string, value any) any {
+	if m.disable || value == nil {
+		return value
+	}
+	if m.matchKey(key) {
+		return RedactedValue
+	}
+	switch v := value.(type) {
+	case string:
+		return m.maskString(v)
+	case map[string]any:
+		return m.maskMap(v, false)
+	case http.Header:
+		return MaskHeader(v)
+	}
+	return value
+}
+
+// maskMap 对 map 脱敏，header 为 true 时按请求头规则处理
+func (m *Masker) maskMap(values map[string]any, header bool) map[string]any {
+	masked := make(map[string]any, len(values))
+	for k, v := range values {
+		if header {
+			switch m.headerValue(k) {
+			case headerDrop:
+				continue
+			case headerRedact:
+				masked[k] = RedactedValue
+				continue
+			}
+		}
+		masked[k] = m.maskValue(k, v)
+	}
+	return masked
+}
+
+// maskField 对日志字段脱敏
+func (m *Masker) maskField(f zapcore.Field) zapcore.Field {
+	if m.matchKey(f.Key) {
+		return zap.String(f.Key, RedactedValue)
+	}
+	switch f.Type {
+	case zapcore.StringType:
+		f.String = m.maskString(f.String)
+	case zapcore.ByteStringType:
+		if b, ok := f.Interface.([]byte); ok {
+			f.Interface = []byte(m.maskString(string(b)))
+		}
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return zap.String(f.Key, m.maskString(err.Error()))
+		}
+	case zapcore.StringerType:
+		if v, ok := f.Interface.(fmt.Stringer); ok {
+			return zap.String(f.Key, m.maskString(stringerValue(v)))
+		}
+	case zapcore.ReflectType:
+		switch v := f.Interface.(type) {
+		case map[string]any:
+			// x_header 为请求头
+			f.Interface = m.maskMap(v, f.Key == "x_header")
+		case http.Header:
+			f.Interface = MaskHeader(v)
+		case string:
+			f.Interface = m.maskString(v)
+		}
+	}
+	return f
+}
+
+// stringerValue 获取 String() 的结果，与 zap 一致，nil 指针输出 <nil>，其他 panic 输出错误信息
+func stringerValue(v fmt.Stringer) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+				s = "<nil>"
+				return
+			}
+			s = fmt.Sprintf("PANIC=%v", r)
+		}
+	}()
+	return v.String()
+}
+
+// maskFields 对日志字段脱敏，返回新的字段列表
+func (m *Masker) maskFields(fields []zapcore.Field) []zapcore.Field {
+	masked := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		masked[i] = m.maskField(f)
+	}
+	return masked
+}
+
+// maskURL 对 URL 中的查询参数脱敏
+func maskURL(u *url.URL) string {
+	masked := *u
+	masked.RawQuery = maskQuery(u.RawQuery)
+	return masker.Load().maskString(masked.String())
+}
+
+// maskQuery 对查询参数或表单脱敏，参数名匹配敏感规则时整体脱敏
+func maskQuery(rawQuery string) string {
+	m := masker.Load()
+	if m.disable || rawQuery == "" {
+		return rawQuery
+	}
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// 存在无法解析的参数时按 key=value 逐个匹配参数名
+		return m.maskString(m.maskPairs(queryPairPattern, rawQuery))
+	}
+	for k, vs := range q {
+		for i, v := range vs {
+			if m.matchKey(k) {
+				vs[i] = RedactedValue
+			} else {
+				vs[i] = m.maskString(v)
+			}
+		}
+	}
+	// * 在查询参数中无需转义，保持脱敏结果可读
+	return strings.ReplaceAll(q.Encode(), "%2A", "*")
+}
+
+// maskBody 对请求或响应内容脱敏
+// JSON 及表单按字段名脱敏，无法解析时（如内容被截断）按 "key":value 或 key=value 匹配字段名后再按值规则脱敏
+func maskBody(contentType string, body []byte) string {
+	m := masker.Load()
+	if m.disable {
+		return string(body)
+	}
+	switch {
+	case strings.Contains(contentType, "json"):
+		// 使用 json.Number 避免长整型 ID 丢失精度
+		var v any
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err == nil {
+			if masked, err := json.Marshal(m.maskJSON("", v)); err == nil {
+				return string(masked)
+			}
+		}
+		return m.maskString(m.maskPairs(jsonPairPattern, string(body)))
+	case strings.Contains(contentType, "x-www-form-urlencoded"):
+		return maskQuery(string(body))
+	}
+	return m.maskString(string(body))
+}
+
+var (
+	// jsonPairPattern JSON 中的 "key":value，字符串值可能被截断而缺少结尾的引号，对象及数组的值不匹配
+	jsonPairPattern = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"\s*:\s*("(?:[^"\\]|\\.)*"?|[^\s,{}\[\]"]+)`)
+	// queryPairPattern 查询参数或表单中的 key=value
+	queryPairPattern = regexp.MustCompile(`(?:^|&)([^=&]*)=([^&]*)`)
+)
+
+// maskPairs 将 re 匹配的 key/value 中字段名匹配敏感规则的值替换为 ******
+// re 的第一个分组为字段名，第二个分组为值
+func (m *Masker) maskPairs(re *regexp.Regexp, s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+		key := s[loc[2]:loc[3]]
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		if !m.matchKey(key) {
+			continue
+		}
+		value := RedactedValue
+		if strings.HasPrefix(s[loc[4]:loc[5]], `"`) {
+			value = `"` + RedactedValue + `"`
+		}
+		b.WriteString(s[last:loc[4]])
+		b.WriteString(value)
+		last = loc[5]
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// maskJSON 对 JSON 解析后的值递归脱敏
+func (m *Masker) maskJSON(key string, value any) any {
+	if key != "" && m.matchKey(key) {
+		return RedactedValue
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for k, val := range v {
+			v[k] = m.maskJSON(k, val)
+		}
+	case []any:
+		for i, val := range v {
+			v[i] = m.maskJSON("", val)
+		}
+	case string:
+		return m.maskString(v)
+	case json.Number:
+		// 以数字发送的手机号、银行卡号等，脱敏后以字符串输出
+		if masked := m.maskString(v.String()); masked != v.String() {
+			return masked
+		}
+	}
+	return value
+}
+
+// maskCore 写入前对日志内容及字段脱敏
+// 需要包装在单个输出的 Core 外层，Tee 写入时不会再次判断各个 Core 的级别
+type maskCore struct {
+	zapcore.Core
+}
+
+// With 实现 zapcore.Core 接口，添加的字段在添加时脱敏
+func (c *maskCore) With(fields []zapcore.Field) zapcore.Core {
+	m := masker.Load()
+	if m.disable {
+		return &maskCore{Core: c.Core.With(fields)}
+	}
+	return &maskCore{Core: c.Core.With(m.maskFields(fields))}
+}
+
+// Check 实现 zapcore.Core 接口
+func (c *maskCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 实现 zapcore.Core 接口，对日志内容及字段脱敏后写入
+func (c *maskCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	m := masker.Load()
+	if m.disable {
+		return c.Core.Write(ent, fields)
+	}
+	ent.Message = m.maskString(ent.Message)
+	return c.Core.Write(ent, m.maskFields(fields))
+}
+
+// luhnValid 使用 Luhn 算法校验银行卡号，减少订单号等长数字被误脱敏
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
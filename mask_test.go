@@ -0,0 +1,183 @@
+package gohera
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// setTestMasker 替换脱敏配置，测试结束后恢复
+func setTestMasker(t *testing.T, cfg maskConfig) {
+	saved := masker.Load()
+	if err := applyMaskConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		masker.Store(saved)
+	})
+}
+
+type testStringer struct{ s string }
+
+func (s *testStringer) String() string { return s.s }
+
+func TestMaskString(t *testing.T) {
+	setTestMasker(t, defaultMaskConfig())
+	for _, tt := range []struct{ in, want string }{
+		{"手机号 13812345678", "手机号 138****5678"},
+		{"身份证 110101199003071234", "身份证 110***********1234"},
+		{"卡号 4111111111111111", "卡号 4111********1111"},
+		// 不满足 Luhn 校验的长数字不脱敏
+		{"订单 4111111111111112", "订单 4111111111111112"},
+		{"无敏感内容", "无敏感内容"},
+	} {
+		if got := MaskString(tt.in); got != tt.want {
+			t.Errorf("MaskString(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMaskValueAndHeader(t *testing.T) {
+	setTestMasker(t, defaultMaskConfig())
+	if got := MaskValue("password", "p"); got != RedactedValue {
+		t.Errorf("MaskValue(password) = %v", got)
+	}
+	m := MaskValue("user", map[string]any{"phone": "13812345678", "token": "abc", "age": 18}).(map[string]any)
+	if m["phone"] != "138****5678" || m["token"] != RedactedValue || m["age"] != 18 {
+		t.Errorf("MaskValue(map) = %v", m)
+	}
+
+	h := MaskHeader(http.Header{"Authorization": {"Bearer abc"}, "X-Phone": {"13812345678"}, "Accept": {"*/*"}})
+	if h.Get("Authorization") != RedactedValue || h.Get("X-Phone") != "138****5678" || h.Get("Accept") != "*/*" {
+		t.Errorf("MaskHeader() = %v", h)
+	}
+	cfg := defaultMaskConfig()
+	cfg.HeaderAllow = []string{"Accept"}
+	setTestMasker(t, cfg)
+	if h = MaskHeader(http.Header{"Accept": {"*/*"}, "X-Phone": {"1"}}); len(h) != 1 {
+		t.Errorf("MaskHeader() with header_allow = %v", h)
+	}
+}
+
+func TestMaskQueryAndBody(t *testing.T) {
+	setTestMasker(t, defaultMaskConfig())
+	if got := maskQuery("phone=13812345678&access_token=abc"); got != "access_token=******&phone=138****5678" {
+		t.Errorf("maskQuery() = %q", got)
+	}
+	got := maskBody("application/json", []byte(`{"id":12345678901234567890,"password":"p","user":{"phone":"13812345678"}}`))
+	if got != `{"id":12345678901234567890,"password":"******","user":{"phone":"138****5678"}}` {
+		t.Errorf("maskBody(json) = %s", got)
+	}
+	// 以数字发送的敏感内容脱敏后以字符串输出
+	got = maskBody("application/json", []byte(`{"phone":13812345678,"card":6222020200112233446,"amount":1.5}`))
+	if got != `{"amount":1.5,"card":"6222***********3446","phone":"138****5678"}` {
+		t.Errorf("maskBody(json number) = %s", got)
+	}
+	// 被截断的 JSON 按值规则脱敏
+	if got = maskBody("application/json", []byte(`{"phone":"13812345678`)); !strings.Contains(got, "138****5678") {
+		t.Errorf("maskBody(truncated) = %s", got)
+	}
+}
+
+func TestMaskTruncatedBody(t *testing.T) {
+	setTestMasker(t, defaultMaskConfig())
+	// 超过 max_body_size 被截断的 JSON 仍按字段名脱敏
+	body := `{"user":{"name":"a","password": "p@ss\"word"},"token":"tk-123","retry":3,"auth":{"access_token":"tk-4`
+	want := `{"user":{"name":"a","password": "******"},"token":"******","retry":3,"auth":{"access_token":"******"`
+	if got := maskBody("application/json", []byte(body)); got != want {
+		t.Errorf("maskBody(truncated) = %s, want %s", got, want)
+	}
+	if got := maskBody("application/x-www-form-urlencoded", []byte("phone=13812345678&token=tk-123&pwd=a%zz&passw")); got != "phone=138****5678&token=******&pwd=******&passw" {
+		t.Errorf("maskBody(truncated form) = %s", got)
+	}
+	if got := maskQuery("q=%zz&access_token=tk-123"); got != "q=%zz&access_token=******" {
+		t.Errorf("maskQuery(invalid) = %s", got)
+	}
+}
+
+func TestMaskCore(t *testing.T) {
+	setTestMasker(t, defaultMaskConfig())
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(&maskCore{Core: core}).With(zap.String("secret", "s"))
+	log.Info("call 13812345678",
+		zap.String("phone", "13812345678"),
+		zap.Error(errors.New("user 13812345678 not found")),
+		zap.Stringer("user", &testStringer{"13812345678"}),
+		zap.Stringer("nil", (*testStringer)(nil)),
+		zap.Any("x_header", map[string]any{"Authorization": "Bearer abc"}),
+	)
+
+	e := logs.TakeAll()[0]
+	m := e.ContextMap()
+	if e.Message != "call 138****5678" {
+		t.Errorf("message = %q", e.Message)
+	}
+	want := map[string]any{
+		"secret":   RedactedValue,
+		"phone":    "138****5678",
+		"error":    "user 138****5678 not found",
+		"user":     "138****5678",
+		"nil":      "<nil>",
+		"x_header": map[string]any{"Authorization": RedactedValue},
+	}
+	for k, v := range want {
+		if got := m[k]; !equalValue(got, v) {
+			t.Errorf("%s = %v, want %v", k, got, v)
+		}
+	}
+
+	setTestMasker(t, maskConfig{Disable: true})
+	log.Info("13812345678")
+	if got := logs.TakeAll()[0].Message; got != "13812345678" {
+		t.Errorf("message = %q, want unmasked when disabled", got)
+	}
+}
+
+// equalValue 比较字段值，map 按元素比较
+func equalValue(a, b any) bool {
+	am, ok1 := a.(map[string]any)
+	bm, ok2 := b.(map[string]any)
+	if !ok1 || !ok2 {
+		return a == b
+	}
+	if len(am) != len(bm) {
+		return false
+	}
+	for k := range am {
+		if am[k] != bm[k] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSlogOutputMasked(t *testing.T) {
+	setTestMasker(t, defaultMaskConfig())
+	resetLogLevels(t)
+	buf := setTestSlogOutput(t, slog.LevelDebug)
+	ctx := context.WithValue(context.Background(), TraceCtx, &Trace{TraceId: "t", Headers: map[string]any{"Authorization": "Bearer abc"}})
+
+	ErrorFields(ctx, "call 13812345678", zap.String("password", "p"), zap.Error(errors.New("phone 13812345678")))
+	Ctx(ctx).With("token", "abc").Info("ok")
+
+	out := buf.String()
+	for _, leak := range []string{"13812345678", "Bearer abc", `"p"`, `"abc"`} {
+		if strings.Contains(out, leak) {
+			t.Errorf("slog output leaks %s: %s", leak, out)
+		}
+	}
+	lines := decodeSlogLines(t, buf)
+	if lines[0]["msg"] != "call 138****5678" || lines[0]["password"] != RedactedValue || lines[0]["error"] != "phone 138****5678" {
+		t.Errorf("line = %v", lines[0])
+	}
+	if lines[1]["token"] != RedactedValue {
+		t.Errorf("line = %v", lines[1])
+	}
+}
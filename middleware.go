@@ -2,6 +2,7 @@ package gohera
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -11,37 +12,56 @@ import (
 )
 
 // TraceContext 生成链路追踪 ID 的中间件
-// 会检查 Request Header 中的 TraceId, SpanId，如果不存在则生成新的
+// 按 [trace] propagators 配置的顺序从 Request Header 中提取 Trace 信息，如果不存在则生成新的
 func TraceContext() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		traceID := c.GetHeader(TraceId)
-		spanID := c.GetHeader(SpanId)
-		if traceID == "" {
-			if c.GetHeader("HTTP_TRACE_ID") != "" {
-				traceID = c.GetHeader("HTTP_TRACE_ID")
-			} else if c.GetHeader("TRACE_ID") != "" {
-				traceID = c.GetHeader("TRACE_ID")
-			} else {
-				traceID = strings.ReplaceAll(uuid.NewString(), "-", "")
-			}
+		t := &Trace{
+			UserId:    CurrentUserId(c),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Host + c.Request.URL.Path,
+			Status:    c.Writer.Status(),
+			Headers:   getHeader(c.Request.Header),
+			HexSpanId: newHexSpanId(),
 		}
-		if spanID == "" {
-			spanID = SpanIdDefault
+		tracePropagator.Load().Extract(c.Request.Header, t)
+		if t.TraceId == "" {
+			t.TraceId = strings.ReplaceAll(uuid.NewString(), "-", "")
 		}
-		t := &Trace{
-			TraceId: traceID,
-			SpanId:  spanID,
-			UserId:  c.GetInt(UserId),
-			Method:  c.Request.Method,
-			Path:    c.Request.URL.Host + c.Request.URL.Path,
-			Status:  c.Writer.Status(),
-			Headers: getHeader(c.Request.Header),
+		if t.SpanId == "" {
+			t.SpanId = SpanIdDefault
 		}
 		c.Set(TraceCtx, t)
 		// 将 Trace 信息注入到 Request 的 Context 中，以便在非 Gin 环境下（如 Service 层）也能获取
 		ctx := context.WithValue(c.Request.Context(), TraceCtx, t)
+
+		// 开启 [trace.export] 时为请求创建 Span，采样决定通过 Trace 传递给下游
+		ctx, span := startSpan(ctx, t, c.Request.Method, SpanKindServer, t.HexSpanId, t.HexParentId)
+		if span != nil {
+			sampled := span.Sampled()
+			t.Sampled = &sampled
+			c.Set(spanCtx, span)
+		}
 		c.Request = c.Request.WithContext(ctx)
 		c.Next()
+
+		if span != nil {
+			if route := c.FullPath(); route != "" {
+				span.Name = c.Request.Method + " " + route
+				span.SetAttribute("http.route", route)
+			}
+			status := c.Writer.Status()
+			span.SetAttribute("http.method", c.Request.Method)
+			span.SetAttribute("http.target", c.Request.URL.Path)
+			span.SetAttribute("http.status_code", status)
+			span.SetAttribute("http.client_ip", c.ClientIP())
+			span.SetAttribute("http.user_agent", c.Request.UserAgent())
+			if len(c.Errors) > 0 {
+				span.SetError(errors.New(c.Errors.String()))
+			} else if status >= http.StatusInternalServerError {
+				span.SetError(errors.New(http.StatusText(status)))
+			}
+			span.End()
+		}
 	}
 }
 
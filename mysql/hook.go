@@ -0,0 +1,59 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"xorm.io/xorm/contexts"
+)
+
+// Hook SQL 执行的钩子，用于链路追踪、日志等
+// 使用 DB.Context(ctx) 创建的 Session 执行 SQL 时，钩子能够从 ctx 中获取 Trace 信息
+type Hook interface {
+	// BeforeQuery SQL 执行前调用，返回的 Context 传递给 AfterQuery
+	BeforeQuery(ctx context.Context, query string, args []any) context.Context
+	// AfterQuery SQL 执行后调用，result 只在执行 Exec 类语句时不为 nil
+	AfterQuery(ctx context.Context, query string, args []any, result sql.Result, duration time.Duration, err error)
+}
+
+var (
+	hooks   []Hook
+	hooksMu sync.RWMutex
+)
+
+// AddHook 添加所有数据库连接共用的 SQL 钩子
+func AddHook(h Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, h)
+}
+
+// getHooks 获取已添加的钩子
+func getHooks() []Hook {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	return hooks
+}
+
+// xormHook 将 xorm 的钩子转换为 Hook
+// xorm 组合多个钩子时只保留最后一个钩子返回的 Context，因此只注册这一个钩子，由它依次调用所有 Hook
+type xormHook struct{}
+
+// BeforeProcess 实现 contexts.Hook 接口
+func (xormHook) BeforeProcess(c *contexts.ContextHook) (context.Context, error) {
+	ctx := c.Ctx
+	for _, h := range getHooks() {
+		ctx = h.BeforeQuery(ctx, c.SQL, c.Args)
+	}
+	return ctx, nil
+}
+
+// AfterProcess 实现 contexts.Hook 接口
+func (xormHook) AfterProcess(c *contexts.ContextHook) error {
+	for _, h := range getHooks() {
+		h.AfterQuery(c.Ctx, c.SQL, c.Args, c.Result, c.ExecuteTime, c.Err)
+	}
+	return nil
+}
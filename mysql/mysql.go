@@ -13,15 +13,15 @@ import (
 )
 
 type Config struct {
-	MaxLifeTime  time.Duration `toml:"max_life_time"`  // 设置连接可以被重新使用的最大时间量
-	MaxOpenConns int           `toml:"max_open_conns"` // 设置打开连接到数据库的最大数量
-	MaxIdleConns int           `toml:"max_idle_conns"` // 设置空闲连接池中的最大连接数
-	User         string        `toml:"user"`           // 用户名
-	Password     string        `toml:"password"`       // 密码
-	Host         string        `toml:"host"`           // 数据库地址
-	Port         int           `toml:"port"`           // 端口
-	Database     string        `toml:"database"`       // 连接那个数据库
-	Env          string
+	MaxLifeTime  time.Duration `toml:"max_life_time" mapstructure:"max_life_time"`                   // 设置连接可以被重新使用的最大时间量
+	MaxOpenConns int           `toml:"max_open_conns" mapstructure:"max_open_conns" binding:"gte=0"` // 设置打开连接到数据库的最大数量
+	MaxIdleConns int           `toml:"max_idle_conns" mapstructure:"max_idle_conns" binding:"gte=0"` // 设置空闲连接池中的最大连接数
+	User         string        `toml:"user" mapstructure:"user" binding:"required"`                  // 用户名
+	Password     string        `toml:"password" mapstructure:"password"`                             // 密码
+	Host         string        `toml:"host" mapstructure:"host" binding:"required"`                  // 数据库地址
+	Port         int           `toml:"port" mapstructure:"port"`                                     // 端口
+	Database     string        `toml:"database" mapstructure:"database" binding:"required"`          // 连接那个数据库
+	Env          string        `mapstructure:"-"`
 }
 
 // 变量初始化
@@ -84,9 +84,9 @@ func (o *ConnectPool) Connect() (*DB, error) {
 	// 设置可重用连接的最长时间，一定要小于mysql服务端的保持超时时间，否则可能会被服务端关闭
 	obj.DB().SetConnMaxLifetime(o.config.MaxLifeTime)
 	obj.SetMapper(names.GonicMapper{})
-	// 非生产环境开启sql日志
+	obj.AddHook(xormHook{})
+	// SQL 日志通过 AddHook 添加的钩子记录，能够从 Context 中获取 Trace 信息
 	if strings.ToUpper(o.config.Env) == "DEV" || strings.ToUpper(o.config.Env) == "TEST" {
-		obj.ShowSQL(true)
 		obj.DB().SetConnMaxLifetime(1 * time.Minute)
 	}
 	dbMap[o.config.Database] = obj
@@ -270,18 +270,26 @@ func (h *HTTPRequest) setTrace(cx context.Context, req *http.Request) context.Co
 		spanId = traceInfo.SpanId + "." + strconv.FormatInt(int64(index)+1, 10)
 
 		newTrace := &Trace{
-			TraceId: traceInfo.TraceId,
-			SpanId:  spanId,
-			UserId:  traceInfo.UserId,
-			Method:  traceInfo.Method,
-			Path:    req.URL.Host + req.URL.Path,
-			Status:  gCtx.Writer.Status(),
-			Headers: getHeader(h.header),
+			TraceId:     traceInfo.TraceId,
+			SpanId:      spanId,
+			UserId:      traceInfo.UserId,
+			Method:      traceInfo.Method,
+			Path:        req.URL.Host + req.URL.Path,
+			Status:      gCtx.Writer.Status(),
+			Headers:     getHeader(h.header),
+			HexSpanId:   newHexSpanId(),
+			HexParentId: currentSpanId(gCtx),
+			Sampled:     traceInfo.Sampled,
+			TraceState:  traceInfo.TraceState,
+			Baggage:     traceInfo.Baggage,
 		}
 		gCtx.Set(TraceCtx, newTrace)
 		traceInfo = newTrace
 		cx = context.WithValue(gCtx.Request.Context(), TraceCtx, traceInfo)
 	} else {
+		// 非 Gin 环境下沿用 Context 中已有的 Trace (如 c.Request.Context())，没有时生成新的
+		parent := GetTraceContext(cx)
+		traceInfo.SpanId = parent.SpanId
 		if h.header.Get(SpanId) != "" {
 			traceInfo.SpanId = h.header.Get(SpanId)
 		}
@@ -292,13 +300,18 @@ func (h *HTTPRequest) setTrace(cx context.Context, req *http.Request) context.Co
 		index, _ := strconv.Atoi(indexArr[len(indexArr)-1])
 		spanId = traceInfo.SpanId + "." + strconv.FormatInt(int64(index)+1, 10)
 		traceInfo = &Trace{
-			TraceId: strings.ReplaceAll(uuid.NewString(), "-", ""),
-			SpanId:  spanId,
-			UserId:  0,
-			Method:  h.method,
-			Path:    h.url,
-			Status:  200,
-			Headers: getHeader(h.header),
+			TraceId:     Ternary[string](parent.TraceId == "", strings.ReplaceAll(uuid.NewString(), "-", ""), parent.TraceId),
+			SpanId:      spanId,
+			UserId:      parent.UserId,
+			Method:      h.method,
+			Path:        h.url,
+			Status:      200,
+			Headers:     getHeader(h.header),
+			HexSpanId:   newHexSpanId(),
+			HexParentId: currentSpanId(cx),
+			Sampled:     parent.Sampled,
+			TraceState:  parent.TraceState,
+			Baggage:     parent.Baggage,
 		}
 		cx = context.WithValue(cx, TraceCtx, traceInfo)
 	}
@@ -308,13 +321,13 @@ func (h *HTTPRequest) setTrace(cx context.Context, req *http.Request) context.Co
 			req.Header.Set(k, v1)
 		}
 	}
-	req.Header.Set(SpanId, spanId)
-	req.Header.Set(TraceId, traceInfo.TraceId)
+	// 按 [trace] propagators 配置写入所有格式的请求头
+	tracePropagator.Load().Inject(traceInfo, req.Header)
 	return cx
 }
 
 // 发起http请求,获取响应并设置对应的值
-func (h *HTTPRequest) doRequest(ctx context.Context) *HTTPRespone {
+func (h *HTTPRequest) doRequest(ctx context.Context) (hr *HTTPRespone) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -335,7 +348,7 @@ func (h *HTTPRequest) doRequest(ctx context.Context) *HTTPRespone {
 		u.RawQuery = q.Encode()
 	}
 
-	Infotf(ctx, "request %v: %v", h.method, u.String())
+	Infotf(ctx, "request %v: %v", h.method, maskURL(u))
 
 	req, err := http.NewRequestWithContext(ctx, h.method, u.String(), nil)
 	if err != nil {
@@ -348,6 +361,22 @@ func (h *HTTPRequest) doRequest(ctx context.Context) *HTTPRespone {
 	h.setBody(req)
 	newCtx := h.setTrace(ctx, req)
 	req = req.WithContext(newCtx)
+
+	// 开启 [trace.export] 时为请求创建 Span，Span ID 与写入请求头的一致
+	t := GetTraceContext(newCtx)
+	_, span := startSpan(newCtx, t, "HTTP "+h.method, SpanKindClient, t.HexSpanId, t.HexParentId)
+	if span != nil {
+		span.SetAttribute("http.method", h.method)
+		span.SetAttribute("http.url", maskURL(u))
+		defer func() {
+			span.SetAttribute("http.status_code", hr.responseCode)
+			span.SetError(hr.Error)
+			if hr.Error == nil && hr.responseCode >= http.StatusBadRequest {
+				span.SetError(errors.New(http.StatusText(hr.responseCode)))
+			}
+			span.End()
+		}()
+	}
 	h.setReferer(newCtx, req)
 
 	if h.transport != nil {
@@ -358,7 +387,7 @@ func (h *HTTPRequest) doRequest(ctx context.Context) *HTTPRespone {
 	var resp *http.Response
 	for i := 0; h.retries == -1 || i <= h.retries; i++ {
 		if i > 0 {
-			Infotf(newCtx, "retry request %v: %v, times: %d", h.method, u.String(), i)
+			Infotf(newCtx, "retry request %v: %v, times: %d", h.method, maskURL(u), i)
 		}
 		resp, err = h.client.Do(req)
 		if err == nil {
@@ -395,7 +424,7 @@ func (h *HTTPRequest) doRequest(ctx context.Context) *HTTPRespone {
 		return &HTTPRespone{Error: err, responseCode: resp.StatusCode}
 	}
 
-	hr := &HTTPRespone{
+	hr = &HTTPRespone{
 		responseCode:   resp.StatusCode,
 		responseCookie: resp.Cookies(),
 		responseHeader: resp.Header,
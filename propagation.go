@@ -0,0 +1,312 @@
+package gohera
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// PropagatorLegacy 框架原有的 x-trace-id/x-span-id 请求头
+	PropagatorLegacy = "legacy"
+	// PropagatorTraceContext W3C Trace Context 的 traceparent/tracestate 请求头
+	PropagatorTraceContext = "tracecontext"
+	// PropagatorBaggage W3C Baggage 的 baggage 请求头
+	PropagatorBaggage = "baggage"
+	// PropagatorB3 B3 单请求头 (b3)
+	PropagatorB3 = "b3"
+	// PropagatorB3Multi B3 多请求头 (X-B3-TraceId 等)
+	PropagatorB3Multi = "b3multi"
+)
+
+// Propagator 在请求头中传递 Trace 信息
+// 中间件接收请求时按配置的顺序调用 Extract，HTTP 客户端发起请求时调用所有 Inject
+type Propagator interface {
+	// Extract 从请求头中提取 Trace 信息写入 t，t.TraceId 已有值时说明已被之前的 Propagator 提取，不应再覆盖；
+	// 请求头中的 TraceId 与其相同时只补充尚未提取的字段
+	Extract(header http.Header, t *Trace)
+	// Inject 将 t 写入请求头
+	Inject(t *Trace, header http.Header)
+}
+
+var (
+	propagators = map[string]Propagator{
+		PropagatorLegacy:       legacyPropagator{},
+		PropagatorTraceContext: traceContextPropagator{},
+		PropagatorBaggage:      baggagePropagator{},
+		PropagatorB3:           b3Propagator{},
+		PropagatorB3Multi:      b3Propagator{multi: true},
+	}
+	propagatorsMu sync.RWMutex
+)
+
+// RegisterPropagator 注册自定义的 Propagator，之后可以在 [trace] propagators 中使用 name
+func RegisterPropagator(name string, p Propagator) {
+	propagatorsMu.Lock()
+	defer propagatorsMu.Unlock()
+	propagators[name] = p
+}
+
+// compositePropagator 按顺序组合多个 Propagator
+type compositePropagator []Propagator
+
+// newPropagator 根据名称组合 Propagator
+func newPropagator(names []string) (compositePropagator, error) {
+	propagatorsMu.RLock()
+	defer propagatorsMu.RUnlock()
+	var cp compositePropagator
+	for _, name := range names {
+		p, ok := propagators[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown trace propagator: %s", name)
+		}
+		cp = append(cp, p)
+	}
+	return cp, nil
+}
+
+// Extract 实现 Propagator 接口
+func (cp compositePropagator) Extract(header http.Header, t *Trace) {
+	for _, p := range cp {
+		p.Extract(header, t)
+	}
+}
+
+// Inject 实现 Propagator 接口
+func (cp compositePropagator) Inject(t *Trace, header http.Header) {
+	for _, p := range cp {
+		p.Inject(t, header)
+	}
+}
+
+// tracePropagator 当前使用的 Propagator
+var tracePropagator atomic.Pointer[compositePropagator]
+
+func init() {
+	cp, _ := newPropagator(defaultTraceConfig().Propagators)
+	tracePropagator.Store(&cp)
+}
+
+// legacyPropagator 框架原有的请求头，兼容 HTTP_TRACE_ID/TRACE_ID
+type legacyPropagator struct{}
+
+// Extract 实现 Propagator 接口
+func (legacyPropagator) Extract(header http.Header, t *Trace) {
+	var traceId string
+	for _, key := range []string{TraceId, "HTTP_TRACE_ID", "TRACE_ID"} {
+		if traceId = header.Get(key); traceId != "" {
+			break
+		}
+	}
+	if traceId == "" || !sameTraceId(t, traceId) {
+		return
+	}
+	t.TraceId = traceId
+	if t.SpanId == "" {
+		t.SpanId = header.Get(SpanId)
+	}
+}
+
+// Inject 实现 Propagator 接口
+func (legacyPropagator) Inject(t *Trace, header http.Header) {
+	header.Set(SpanId, t.SpanId)
+	header.Set(TraceId, t.TraceId)
+}
+
+// traceContextPropagator W3C Trace Context，traceparent 格式为 00-{trace-id}-{parent-id}-{flags}
+type traceContextPropagator struct{}
+
+// Extract 实现 Propagator 接口
+func (traceContextPropagator) Extract(header http.Header, t *Trace) {
+	parts := strings.Split(strings.TrimSpace(header.Get("traceparent")), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return
+	}
+	if !isHexId(parts[1], 32) || !isHexId(parts[2], 16) || len(parts[3]) != 2 {
+		return
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return
+	}
+	if !sameTraceId(t, parts[1]) {
+		return
+	}
+	t.TraceId = parts[1]
+	if t.HexParentId == "" {
+		t.HexParentId = parts[2]
+	}
+	if t.Sampled == nil {
+		sampled := flags[0]&1 == 1
+		t.Sampled = &sampled
+	}
+	if t.TraceState == "" {
+		t.TraceState = header.Get("tracestate")
+	}
+}
+
+// Inject 实现 Propagator 接口，TraceId 不是 32 位十六进制时无法使用 W3C 格式，不写入
+func (traceContextPropagator) Inject(t *Trace, header http.Header) {
+	if !isHexId(t.TraceId, 32) || !isHexId(t.HexSpanId, 16) {
+		return
+	}
+	flags := "01"
+	if t.Sampled != nil && !*t.Sampled {
+		flags = "00"
+	}
+	header.Set("traceparent", "00-"+t.TraceId+"-"+t.HexSpanId+"-"+flags)
+	if t.TraceState != "" {
+		header.Set("tracestate", t.TraceState)
+	}
+}
+
+// baggagePropagator W3C Baggage，格式为 key1=value1,key2=value2;property
+type baggagePropagator struct{}
+
+// baggageMaxSize baggage 请求头的最大长度
+const baggageMaxSize = 8192
+
+// Extract 实现 Propagator 接口，与已有的 Baggage 合并
+func (baggagePropagator) Extract(header http.Header, t *Trace) {
+	for _, value := range header.Values("baggage") {
+		for _, member := range strings.Split(value, ",") {
+			member, _, _ = strings.Cut(member, ";")
+			k, v, ok := strings.Cut(member, "=")
+			k = strings.TrimSpace(k)
+			if !ok || k == "" {
+				continue
+			}
+			if uv, err := url.PathUnescape(strings.TrimSpace(v)); err == nil {
+				if t.Baggage == nil {
+					t.Baggage = make(map[string]string)
+				}
+				t.Baggage[k] = uv
+			}
+		}
+	}
+}
+
+// Inject 实现 Propagator 接口，超过最大长度的部分不写入
+func (baggagePropagator) Inject(t *Trace, header http.Header) {
+	if len(t.Baggage) == 0 {
+		return
+	}
+	var b strings.Builder
+	for k, v := range t.Baggage {
+		member := k + "=" + url.PathEscape(v)
+		if b.Len()+len(member)+1 > baggageMaxSize {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(member)
+	}
+	header.Set("baggage", b.String())
+}
+
+// b3Propagator B3 格式，单请求头为 b3: {trace-id}-{span-id}-{sampled}-{parent-id}
+type b3Propagator struct {
+	multi bool
+}
+
+// Extract 实现 Propagator 接口，同时识别单请求头及多请求头
+func (p b3Propagator) Extract(header http.Header, t *Trace) {
+	var traceId, spanId, sampled string
+	if v := header.Get("b3"); v != "" {
+		parts := strings.Split(v, "-")
+		if len(parts) == 1 {
+			// 只有采样标记，没有 Trace 信息
+			sampled = parts[0]
+		} else {
+			traceId, spanId = parts[0], parts[1]
+			if len(parts) > 2 {
+				sampled = parts[2]
+			}
+		}
+	} else {
+		traceId = header.Get("X-B3-TraceId")
+		spanId = header.Get("X-B3-SpanId")
+		sampled = header.Get("X-B3-Sampled")
+		if header.Get("X-B3-Flags") == "1" {
+			sampled = "d"
+		}
+	}
+
+	if traceId != "" {
+		if len(traceId) == 16 {
+			// 64 位 TraceId 左侧补零
+			traceId = strings.Repeat("0", 16) + traceId
+		}
+		if !isHexId(traceId, 32) || !isHexId(spanId, 16) || !sameTraceId(t, traceId) {
+			return
+		}
+		t.TraceId = traceId
+		if t.HexParentId == "" {
+			t.HexParentId = spanId
+		}
+	}
+	if t.Sampled != nil {
+		return
+	}
+	switch sampled {
+	case "1", "d", "true":
+		s := true
+		t.Sampled = &s
+	case "0", "false":
+		s := false
+		t.Sampled = &s
+	}
+}
+
+// Inject 实现 Propagator 接口
+func (p b3Propagator) Inject(t *Trace, header http.Header) {
+	if !isHexId(t.TraceId, 32) || !isHexId(t.HexSpanId, 16) {
+		return
+	}
+	sampled := "1"
+	if t.Sampled != nil && !*t.Sampled {
+		sampled = "0"
+	}
+	if !p.multi {
+		header.Set("b3", t.TraceId+"-"+t.HexSpanId+"-"+sampled)
+		return
+	}
+	header.Set("X-B3-TraceId", t.TraceId)
+	header.Set("X-B3-SpanId", t.HexSpanId)
+	if t.HexParentId != "" {
+		header.Set("X-B3-ParentSpanId", t.HexParentId)
+	}
+	header.Set("X-B3-Sampled", sampled)
+}
+
+// sameTraceId 判断请求头中的 traceId 能否写入 t，t.TraceId 为空或与其相同时返回 true
+func sameTraceId(t *Trace, traceId string) bool {
+	return t.TraceId == "" || t.TraceId == traceId
+}
+
+// isHexId 判断是否为指定长度、小写且不全为 0 的十六进制 ID
+func isHexId(id string, size int) bool {
+	if len(id) != size || strings.Trim(id, "0") == "" {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// newHexSpanId 生成 16 位十六进制的 Span ID
+func newHexSpanId() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,160 @@
+package gohera
+
+import (
+	"net/http"
+	"testing"
+)
+
+const (
+	testHexTraceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testHexSpanId  = "00f067aa0ba902b7"
+)
+
+// extractTrace 使用指定的格式从请求头中提取 Trace 信息
+func extractTrace(t *testing.T, header http.Header, names ...string) *Trace {
+	t.Helper()
+	cp, err := newPropagator(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := new(Trace)
+	cp.Extract(header, tr)
+	return tr
+}
+
+func TestExtractLegacyAndTraceContext(t *testing.T) {
+	header := http.Header{}
+	header.Set(TraceId, testHexTraceId)
+	header.Set(SpanId, "1.2")
+	header.Set("traceparent", "00-"+testHexTraceId+"-"+testHexSpanId+"-00")
+	header.Set("tracestate", "vendor=1")
+
+	// legacy 先提取 TraceId，tracecontext 补充上游 Span ID 及采样标记
+	for _, names := range [][]string{
+		{PropagatorLegacy, PropagatorTraceContext},
+		{PropagatorTraceContext, PropagatorLegacy},
+	} {
+		tr := extractTrace(t, header, names...)
+		if tr.TraceId != testHexTraceId || tr.SpanId != "1.2" || tr.HexParentId != testHexSpanId || tr.TraceState != "vendor=1" {
+			t.Fatalf("%v: trace = %+v", names, tr)
+		}
+		if tr.Sampled == nil || *tr.Sampled {
+			t.Fatalf("%v: sampled = %v, want false", names, tr.Sampled)
+		}
+	}
+}
+
+func TestExtractDifferentTraceId(t *testing.T) {
+	header := http.Header{}
+	header.Set(TraceId, "legacy-trace")
+	header.Set(SpanId, "1.2")
+	header.Set("traceparent", "00-"+testHexTraceId+"-"+testHexSpanId+"-01")
+	header.Set("b3", testHexTraceId+"-"+testHexSpanId+"-0")
+
+	// TraceId 不同时以第一个格式为准
+	tr := extractTrace(t, header, PropagatorLegacy, PropagatorTraceContext, PropagatorB3)
+	if tr.TraceId != "legacy-trace" || tr.SpanId != "1.2" || tr.HexParentId != "" || tr.Sampled != nil {
+		t.Fatalf("trace = %+v", tr)
+	}
+	tr = extractTrace(t, header, PropagatorTraceContext, PropagatorLegacy)
+	if tr.TraceId != testHexTraceId || tr.SpanId != "" || tr.HexParentId != testHexSpanId {
+		t.Fatalf("trace = %+v", tr)
+	}
+}
+
+func TestExtractB3(t *testing.T) {
+	header := http.Header{}
+	header.Set(TraceId, testHexTraceId)
+	header.Set("X-B3-TraceId", testHexTraceId[16:])
+	header.Set("X-B3-SpanId", testHexSpanId)
+	header.Set("X-B3-Flags", "1")
+	tr := extractTrace(t, header, PropagatorLegacy, PropagatorB3Multi)
+	if tr.TraceId != testHexTraceId || tr.HexParentId != "" {
+		t.Fatalf("64-bit B3 trace id should be padded and differ: %+v", tr)
+	}
+
+	header.Set("X-B3-TraceId", testHexTraceId)
+	tr = extractTrace(t, header, PropagatorLegacy, PropagatorB3Multi)
+	if tr.HexParentId != testHexSpanId || tr.Sampled == nil || !*tr.Sampled {
+		t.Fatalf("trace = %+v", tr)
+	}
+
+	// 只有采样标记
+	tr = extractTrace(t, http.Header{"B3": {"0"}}, PropagatorB3)
+	if tr.TraceId != "" || tr.Sampled == nil || *tr.Sampled {
+		t.Fatalf("trace = %+v", tr)
+	}
+}
+
+func TestExtractInvalidTraceParent(t *testing.T) {
+	for _, v := range []string{
+		"",
+		"00-" + testHexTraceId + "-" + testHexSpanId,
+		"ff-" + testHexTraceId + "-" + testHexSpanId + "-01",
+		"00-" + testHexTraceId + "-" + testHexSpanId + "-01-extra",
+		"00-00000000000000000000000000000000-" + testHexSpanId + "-01",
+		"00-" + testHexTraceId + "-" + testHexSpanId + "-zz",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-" + testHexSpanId + "-01",
+	} {
+		if tr := extractTrace(t, http.Header{"Traceparent": {v}}, PropagatorTraceContext); tr.TraceId != "" || tr.Sampled != nil {
+			t.Errorf("traceparent %q: trace = %+v", v, tr)
+		}
+	}
+	// 未来版本可以有更多字段
+	v := "01-" + testHexTraceId + "-" + testHexSpanId + "-01-extra"
+	if tr := extractTrace(t, http.Header{"Traceparent": {v}}, PropagatorTraceContext); tr.TraceId != testHexTraceId {
+		t.Errorf("traceparent %q: trace = %+v", v, tr)
+	}
+}
+
+func TestPropagatorInject(t *testing.T) {
+	sampled := false
+	tr := &Trace{
+		TraceId:     testHexTraceId,
+		SpanId:      "1.2",
+		HexSpanId:   testHexSpanId,
+		HexParentId: "1111111111111111",
+		Sampled:     &sampled,
+		TraceState:  "vendor=1",
+		Baggage:     map[string]string{"tenant": "a b"},
+	}
+	cp, err := newPropagator([]string{PropagatorLegacy, PropagatorTraceContext, PropagatorBaggage, PropagatorB3, PropagatorB3Multi})
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := http.Header{}
+	cp.Inject(tr, header)
+	for key, want := range map[string]string{
+		TraceId:             testHexTraceId,
+		SpanId:              "1.2",
+		"traceparent":       "00-" + testHexTraceId + "-" + testHexSpanId + "-00",
+		"tracestate":        "vendor=1",
+		"baggage":           "tenant=a%20b",
+		"b3":                testHexTraceId + "-" + testHexSpanId + "-0",
+		"X-B3-ParentSpanId": "1111111111111111",
+		"X-B3-Sampled":      "0",
+	} {
+		if got := header.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	// 下游提取后得到相同的 Trace 信息
+	got := extractTrace(t, header, PropagatorLegacy, PropagatorTraceContext, PropagatorBaggage)
+	if got.TraceId != testHexTraceId || got.HexParentId != testHexSpanId || *got.Sampled || got.Baggage["tenant"] != "a b" {
+		t.Fatalf("extracted trace = %+v", got)
+	}
+
+	// TraceId 不是 32 位十六进制时只写入 legacy 请求头
+	header = http.Header{}
+	cp.Inject(&Trace{TraceId: "legacy-trace", SpanId: "1", HexSpanId: testHexSpanId}, header)
+	if header.Get("traceparent") != "" || header.Get("b3") != "" || header.Get(TraceId) != "legacy-trace" {
+		t.Fatalf("header = %v", header)
+	}
+}
+
+func TestNewPropagatorUnknown(t *testing.T) {
+	if _, err := newPropagator([]string{"unknown"}); err == nil {
+		t.Fatal("newPropagator() should fail on unknown name")
+	}
+}
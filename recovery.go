@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type panicEx struct {
@@ -26,7 +27,7 @@ func HandlerRecovery(stack bool) gin.HandlerFunc {
 			if err := recover(); err != nil {
 				var brokenPipe bool
 				var ne *net.OpError
-				if errors.As(err.(error), &ne) {
+				if e, ok := err.(error); ok && errors.As(e, &ne) {
 					var se *os.SyscallError
 					if errors.As(ne.Err, &se) {
 						if strings.Contains(strings.ToLower(se.Error()), "broken pipe") || strings.Contains(strings.ToLower(se.Error()), "connection reset by peer") {
@@ -38,11 +39,15 @@ func HandlerRecovery(stack bool) gin.HandlerFunc {
 				pe.Err = fmt.Sprintf("%s", err)
 				if brokenPipe {
 					pJson, _ := json.Marshal(pe)
-					Error(c, string(pJson), nil)
+					Error(c, string(pJson))
 					JsonAbort(c, ErrSystem, pe.Err)
 				}
 
-				httpRequest, _ := httputil.DumpRequest(c.Request, false)
+				// 请求头及 URL 脱敏后再输出
+				dumpReq := c.Request.Clone(c.Request.Context())
+				dumpReq.Header = MaskHeader(c.Request.Header)
+				dumpReq.RequestURI = maskURL(c.Request.URL)
+				httpRequest, _ := httputil.DumpRequest(dumpReq, false)
 				request := strings.Replace(string(httpRequest), "\r", "|", -1)
 				req := strings.Replace(request, "\n", "|", -1)
 				pe.Request = req
@@ -53,7 +58,8 @@ func HandlerRecovery(stack bool) gin.HandlerFunc {
 					pe.Stack = stack2
 				}
 				pJson, _ := json.Marshal(pe)
-				Error(c, string(pJson), nil)
+				// x_panic 用于告警按错误信息聚合
+				ErrorFields(c, string(pJson), zap.String("x_panic", pe.Err))
 				JsonAbort(c, ErrSystem, pe.Err)
 			}
 		}()
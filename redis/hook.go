@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Hook 命令执行的钩子，用于链路追踪、日志等
+type Hook interface {
+	// BeforeProcess 命令执行前调用，返回的 Context 传递给 AfterProcess
+	BeforeProcess(ctx context.Context, cmd string, args []any) context.Context
+	// AfterProcess 命令执行后调用，key 不存在时返回空值，err 为 nil
+	AfterProcess(ctx context.Context, cmd string, args []any, duration time.Duration, err error)
+}
+
+var (
+	hooks   []Hook
+	hooksMu sync.RWMutex
+)
+
+// AddHook 添加所有客户端共用的命令钩子
+func AddHook(h Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, h)
+}
+
+// Ctx 返回使用 ctx 执行命令的客户端，ctx 传递给钩子并用于控制命令的超时
+func (r *Client) Ctx(ctx context.Context) *Client {
+	return &Client{pool: r.pool, ctx: ctx}
+}
+
+// context 获取执行命令使用的 Context
+func (r *Client) context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// do 从连接池获取连接执行命令
+func (r *Client) do(cmd string, args ...any) (any, error) {
+	return r.process(cmd, args, func(ctx context.Context, conn redis.Conn) (any, error) {
+		return redis.DoContext(conn, ctx, cmd, args...)
+	})
+}
+
+// process 从连接池获取连接执行 fn，并在前后调用钩子
+func (r *Client) process(cmd string, args []any, fn func(ctx context.Context, conn redis.Conn) (any, error)) (any, error) {
+	ctx := r.context()
+	hooksMu.RLock()
+	hs := hooks
+	hooksMu.RUnlock()
+	for _, h := range hs {
+		ctx = h.BeforeProcess(ctx, cmd, args)
+	}
+
+	start := time.Now()
+	reply, err := r.exec(ctx, fn)
+	duration := time.Since(start)
+
+	for _, h := range hs {
+		h.AfterProcess(ctx, cmd, args, duration, err)
+	}
+	return reply, err
+}
+
+// exec 获取连接并执行 fn
+func (r *Client) exec(ctx context.Context, fn func(ctx context.Context, conn redis.Conn) (any, error)) (any, error) {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return fn(ctx, conn)
+}
@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"context"
 	"time"
 
 	"github.com/gomodule/redigo/redis"
@@ -13,9 +14,6 @@ import (
 // required: 本次请求需要消耗的令牌数量 (通常为 1)
 // 返回值: true 表示允许通过，false 表示被限流
 func (r *Client) RateLimit(key string, rate int, capacity int, required int) (bool, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
 	// 获取当前时间（微秒），用于高精度计算
 	now := time.Now().UnixMicro()
 
@@ -68,7 +66,9 @@ func (r *Client) RateLimit(key string, rate int, capacity int, required int) (bo
 	`)
 
 	// 执行脚本
-	res, err := redis.Int(script.Do(conn, key, rate, capacity, required, now))
+	res, err := redis.Int(r.process("EVALSHA", []any{key, rate, capacity, required, now}, func(ctx context.Context, conn redis.Conn) (any, error) {
+		return script.DoContext(ctx, conn, key, rate, capacity, required, now)
+	}))
 	if err != nil {
 		return false, err
 	}
@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -16,6 +17,7 @@ const (
 
 type Client struct {
 	pool *redis.Pool
+	ctx  context.Context
 }
 
 type Config struct {
@@ -72,18 +74,32 @@ func New(cfg *Config) (*Client, error) {
 	}
 
 	return &Client{
-		pool,
+		pool: pool,
 	}, nil
 }
 
-// 返回 int
-func (r *Client) int(cmd string, args ...any) (int, error) {
-	conn := r.pool.Get()
+// Ping 检查 Redis 连接是否可用
+func (r *Client) Ping(ctx context.Context) error {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
 	defer conn.Close()
 
-	reply, e := conn.Do(cmd, args...)
+	_, err = redis.DoContext(conn, ctx, "PING")
+	return err
+}
+
+// Close 关闭连接池，释放所有连接
+func (r *Client) Close() error {
+	return r.pool.Close()
+}
+
+// 返回 int
+func (r *Client) int(cmd string, args ...any) (int, error) {
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Int(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return 0, nil
 	}
 
@@ -92,12 +108,9 @@ func (r *Client) int(cmd string, args ...any) (int, error) {
 
 // 返回 int64
 func (r *Client) int64(cmd string, args ...any) (int64, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Int64(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return 0, nil
 	}
 
@@ -106,12 +119,9 @@ func (r *Client) int64(cmd string, args ...any) (int64, error) {
 
 // 返回 uint64
 func (r *Client) uint64(cmd string, args ...any) (uint64, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Uint64(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return 0, nil
 	}
 
@@ -120,12 +130,9 @@ func (r *Client) uint64(cmd string, args ...any) (uint64, error) {
 
 // 返回 float64
 func (r *Client) float64(cmd string, args ...any) (float64, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Float64(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return 0, nil
 	}
 
@@ -134,12 +141,9 @@ func (r *Client) float64(cmd string, args ...any) (float64, error) {
 
 // 返回 string
 func (r *Client) string(cmd string, args ...any) (string, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.String(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return "", nil
 	}
 
@@ -148,12 +152,9 @@ func (r *Client) string(cmd string, args ...any) (string, error) {
 
 // 返回 bytes
 func (r *Client) bytes(cmd string, args ...any) ([]byte, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Bytes(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return nil, nil
 	}
 
@@ -162,12 +163,9 @@ func (r *Client) bytes(cmd string, args ...any) ([]byte, error) {
 
 // 返回 bool
 func (r *Client) bool(cmd string, args ...any) (bool, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Bool(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return false, nil
 	}
 
@@ -176,12 +174,9 @@ func (r *Client) bool(cmd string, args ...any) (bool, error) {
 
 // 返回 []any
 func (r *Client) values(cmd string, args ...any) ([]any, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Values(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return nil, nil
 	}
 
@@ -190,12 +185,9 @@ func (r *Client) values(cmd string, args ...any) ([]any, error) {
 
 // 返回 []float64
 func (r *Client) float64s(cmd string, args ...any) ([]float64, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Float64s(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return nil, nil
 	}
 
@@ -204,12 +196,9 @@ func (r *Client) float64s(cmd string, args ...any) ([]float64, error) {
 
 // 返回 []string
 func (r *Client) strings(cmd string, args ...any) ([]string, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Strings(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return nil, nil
 	}
 
@@ -218,12 +207,9 @@ func (r *Client) strings(cmd string, args ...any) ([]string, error) {
 
 // 返回 [][]byte
 func (r *Client) byteSlices(cmd string, args ...any) ([][]byte, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.ByteSlices(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return nil, nil
 	}
 
@@ -232,12 +218,9 @@ func (r *Client) byteSlices(cmd string, args ...any) ([][]byte, error) {
 
 // 返回 []int64
 func (r *Client) int64s(cmd string, args ...any) ([]int64, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Int64s(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return nil, nil
 	}
 
@@ -246,12 +229,9 @@ func (r *Client) int64s(cmd string, args ...any) ([]int64, error) {
 
 // 返回 []int
 func (r *Client) ints(cmd string, args ...any) ([]int, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Ints(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return nil, nil
 	}
 
@@ -260,12 +240,9 @@ func (r *Client) ints(cmd string, args ...any) ([]int, error) {
 
 // 返回 map[string]string
 func (r *Client) stringMap(cmd string, args ...any) (map[string]string, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.StringMap(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return nil, nil
 	}
 
@@ -274,12 +251,9 @@ func (r *Client) stringMap(cmd string, args ...any) (map[string]string, error) {
 
 // 返回 map[string]int
 func (r *Client) intMap(cmd string, args ...any) (map[string]int, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.IntMap(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return nil, nil
 	}
 
@@ -288,12 +262,9 @@ func (r *Client) intMap(cmd string, args ...any) (map[string]int, error) {
 
 // 返回 map[string]int64
 func (r *Client) int64Map(cmd string, args ...any) (map[string]int64, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Int64Map(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return nil, nil
 	}
 
@@ -302,12 +273,9 @@ func (r *Client) int64Map(cmd string, args ...any) (map[string]int64, error) {
 
 // 返回 positions
 func (r *Client) positions(cmd string, args ...any) ([]*[2]float64, error) {
-	conn := r.pool.Get()
-	defer conn.Close()
-
-	reply, e := conn.Do(cmd, args...)
+	reply, e := r.do(cmd, args...)
 	v, err := redis.Positions(reply, e)
-	if errors.As(err, &redis.ErrNil) {
+	if errors.Is(err, redis.ErrNil) {
 		return nil, nil
 	}
 
@@ -66,10 +66,27 @@ func (r *httpResponse) GetResult() any {
 type responseBodyWriter struct {
 	gin.ResponseWriter
 	bodyBuf *bytes.Buffer
+	limit   int // 最多缓存的字节数，0 表示不限制
 }
 
 // Write 实现 gin.ResponseWriter 接口，拦截写入内容
 func (w responseBodyWriter) Write(b []byte) (int, error) {
-	w.bodyBuf.Write(b)
+	w.capture(b)
 	return w.ResponseWriter.Write(b)
 }
+
+// WriteString 实现 gin.ResponseWriter 接口，拦截写入内容
+func (w responseBodyWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture 缓存写入的内容，超过 limit 的部分丢弃
+func (w responseBodyWriter) capture(b []byte) {
+	if w.limit > 0 {
+		if remain := w.limit - w.bodyBuf.Len(); remain < len(b) {
+			b = b[:max(remain, 0)]
+		}
+	}
+	w.bodyBuf.Write(b)
+}
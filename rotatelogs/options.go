@@ -13,6 +13,7 @@ const (
 	optkeyRotationSize  = "rotation-size"
 	optkeyRotationCount = "rotation-count"
 	optkeyForceNewFile  = "force-new-file"
+	optkeyCompress      = "compress"
 )
 
 type Option interface {
@@ -97,3 +98,9 @@ func WithHandler(h Handler) Option {
 func ForceNewFile() Option {
 	return SelectorNew(optkeyForceNewFile, true)
 }
+
+// WithCompress 设置是否压缩轮转后的日志文件
+// 轮转后旧文件会在后台压缩为 .gz 文件
+func WithCompress(b bool) Option {
+	return SelectorNew(optkeyCompress, b)
+}
@@ -1,6 +1,7 @@
 package rotatelogs
 
 import (
+	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
@@ -25,6 +26,8 @@ func New(p string, options ...Option) (*RotateLogs, error) {
 	for _, re := range patternConversionRegexps {
 		globPattern = re.ReplaceAllString(globPattern, "*")
 	}
+	// 与 genFilename 保持一致：.log 后缀统一移到文件名末尾，并匹配分代及压缩后的文件
+	globPattern = strings.ReplaceAll(globPattern, ".log", "") + ".log*"
 
 	pattern, err := strftime.New(p)
 	if err != nil {
@@ -39,6 +42,7 @@ func New(p string, options ...Option) (*RotateLogs, error) {
 	var maxAge time.Duration
 	var handler Handler
 	var forceNewFile bool
+	var compress bool
 
 	for _, o := range options {
 		switch o.Name() {
@@ -67,6 +71,8 @@ func New(p string, options ...Option) (*RotateLogs, error) {
 			handler = o.Value().(Handler)
 		case optkeyForceNewFile:
 			forceNewFile = true
+		case optkeyCompress:
+			compress = o.Value().(bool)
 		}
 	}
 
@@ -90,6 +96,7 @@ func New(p string, options ...Option) (*RotateLogs, error) {
 		rotationSize:  rotationSize,
 		rotationCount: rotationCount,
 		forceNewFile:  forceNewFile,
+		compress:      compress,
 	}, nil
 }
 
@@ -218,6 +225,10 @@ func (rl *RotateLogs) getWriter_nolock(bailOnRotateFail, useGenerationalNames bo
 	rl.curFn = filename
 	rl.generation = generation
 
+	if rl.compress && previousFn != "" && previousFn != filename {
+		go compressFile(previousFn)
+	}
+
 	if h := rl.eventHandler; h != nil {
 		go h.Handle(&FileRotatedEvent{
 			prev:    previousFn,
@@ -336,7 +347,7 @@ func (rl *RotateLogs) rotate_nolock(filename string) error {
 	var toUnlink []string
 	for _, path := range matches {
 		// Ignore lock files
-		if strings.HasSuffix(path, "_lock") || strings.HasSuffix(path, "_symlink") {
+		if strings.HasSuffix(path, "_lock") || strings.HasSuffix(path, "_symlink") || strings.HasSuffix(path, ".gz_tmp") {
 			continue
 		}
 
@@ -384,6 +395,46 @@ func (rl *RotateLogs) rotate_nolock(filename string) error {
 	return nil
 }
 
+// compressFile 将轮转后的日志文件压缩为 .gz 文件并删除原文件
+func compressFile(filename string) {
+	if err := gzipFile(filename); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to compress %s: %s\n", filename, err)
+	}
+}
+
+func gzipFile(filename string) error {
+	src, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := filename + ".gz_tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err = os.Rename(tmp, filename+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(filename)
+}
+
 // Close 实现 io.Closer 接口，关闭文件句柄
 func (rl *RotateLogs) Close() error {
 	rl.mutex.Lock()
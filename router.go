@@ -8,7 +8,7 @@ import (
 
 // registerRouter 注册默认路由 (Healthz, 404, 405)
 func registerRouter(engine *gin.Engine) {
-	engine.GET("/healthz", healthCheck)
+	registerHealthRouter(engine)
 	//找不路由报错
 	engine.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusNotFound, newHttpResponse(http.StatusNotFound, "找不到你要的内容,URL:"+c.Request.Host+c.Request.RequestURI, ""))
@@ -0,0 +1,145 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KeyEnv 配置密钥的环境变量
+	KeyEnv = "APP_CONFIG_KEY"
+	// KeyFileEnv 配置密钥文件路径的环境变量，优先级低于 KeyEnv
+	KeyFileEnv = "APP_CONFIG_KEY_FILE"
+
+	prefix = "ENC("
+	suffix = ")"
+
+	// 密文格式：版本号 (1 字节) + salt + nonce + 密文
+	version  = 1
+	saltSize = 16
+
+	// scrypt 参数，单次派生约几十毫秒
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	ErrKeyNotFound = errors.New("secret: config key not found, set " + KeyEnv + " or " + KeyFileEnv)
+	ErrInvalidData = errors.New("secret: invalid encrypted value")
+)
+
+// IsEncrypted 判断是否为 ENC(...) 格式的加密值
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix) && strings.HasSuffix(value, suffix)
+}
+
+// derivedKeys 已派生的 AES 密钥，同一密文在每次热更新时无需重新派生
+var derivedKeys sync.Map
+
+// ParseKey 解析密钥文本，去除首尾空白（如密钥文件末尾的换行）
+// 返回的密钥为口令，加解密时与每个密文的随机 salt 一起通过 scrypt 派生 AES-256 密钥
+func ParseKey(key string) []byte {
+	return []byte(strings.TrimSpace(key))
+}
+
+// deriveKey 使用 scrypt 从口令及 salt 派生 AES-256 密钥
+func deriveKey(passphrase, salt []byte) ([]byte, error) {
+	sum := sha256.Sum256(append(append([]byte{}, salt...), passphrase...))
+	if key, ok := derivedKeys.Load(sum); ok {
+		return key.([]byte), nil
+	}
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	derivedKeys.Store(sum, key)
+	return key, nil
+}
+
+// LoadKey 从环境变量或密钥文件中读取密钥
+func LoadKey() ([]byte, error) {
+	var key []byte
+	if env := os.Getenv(KeyEnv); env != "" {
+		key = ParseKey(env)
+	} else if file := os.Getenv(KeyFileEnv); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key = ParseKey(string(data))
+	}
+	if len(key) == 0 {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// Encrypt 使用口令派生的密钥以 AES-GCM 加密明文，返回 ENC(base64) 格式的密文
+func Encrypt(key []byte, plaintext string) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key, salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	data := append([]byte{version}, salt...)
+	data = append(data, nonce...)
+	data = gcm.Seal(data, nonce, []byte(plaintext), nil)
+	return prefix + base64.StdEncoding.EncodeToString(data) + suffix, nil
+}
+
+// Decrypt 解密 ENC(base64) 格式的密文，非加密值原样返回
+func Decrypt(key []byte, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(value, prefix), suffix))
+	if err != nil || len(data) < 1+saltSize || data[0] != version {
+		return "", ErrInvalidData
+	}
+	salt, data := data[1:1+saltSize], data[1+saltSize:]
+	gcm, err := newGCM(key, salt)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", ErrInvalidData
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", ErrInvalidData
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(passphrase, salt []byte) (cipher.AEAD, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrKeyNotFound
+	}
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
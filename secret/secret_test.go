@@ -0,0 +1,92 @@
+package secret
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := ParseKey("  passphrase\n")
+	if string(key) != "passphrase" {
+		t.Fatalf("ParseKey() = %q", key)
+	}
+	enc, err := Encrypt(key, "p@ssw0rd")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !IsEncrypted(enc) {
+		t.Fatalf("Encrypt() = %s, want ENC(...)", enc)
+	}
+	plain, err := Decrypt(key, enc)
+	if err != nil || plain != "p@ssw0rd" {
+		t.Fatalf("Decrypt() = %q, %v", plain, err)
+	}
+
+	// 每次加密使用随机的 salt 及 nonce
+	enc2, _ := Encrypt(key, "p@ssw0rd")
+	if enc == enc2 {
+		t.Fatal("Encrypt() returned identical ciphertexts for the same plaintext")
+	}
+	data, _ := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(enc, prefix), suffix))
+	data2, _ := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(enc2, prefix), suffix))
+	if data[0] != version || string(data[1:1+saltSize]) == string(data2[1:1+saltSize]) {
+		t.Fatal("ciphertexts do not carry a random salt")
+	}
+}
+
+func TestDecryptInvalid(t *testing.T) {
+	key := ParseKey("passphrase")
+	enc, _ := Encrypt(key, "value")
+	if _, err := Decrypt(ParseKey("other"), enc); !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("Decrypt() with wrong key error = %v, want ErrInvalidData", err)
+	}
+
+	data, _ := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(enc, prefix), suffix))
+	data[len(data)-1] ^= 1
+	tampered := prefix + base64.StdEncoding.EncodeToString(data) + suffix
+	if _, err := Decrypt(key, tampered); !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("Decrypt() tampered error = %v, want ErrInvalidData", err)
+	}
+
+	for _, v := range []string{"ENC(!!!)", "ENC()", "ENC(" + base64.StdEncoding.EncodeToString([]byte{2, 1, 2}) + ")"} {
+		if _, err := Decrypt(key, v); !errors.Is(err, ErrInvalidData) {
+			t.Errorf("Decrypt(%s) error = %v, want ErrInvalidData", v, err)
+		}
+	}
+
+	if plain, err := Decrypt(key, "plain"); err != nil || plain != "plain" {
+		t.Fatalf("Decrypt() plain value = %q, %v", plain, err)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	t.Setenv(KeyEnv, "")
+	t.Setenv(KeyFileEnv, "")
+	if _, err := LoadKey(); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("LoadKey() error = %v, want ErrKeyNotFound", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "config.key")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(KeyFileEnv, file)
+	if key, err := LoadKey(); err != nil || string(key) != "from-file" {
+		t.Fatalf("LoadKey() = %q, %v, want key from file", key, err)
+	}
+
+	t.Setenv(KeyEnv, "from-env")
+	if key, err := LoadKey(); err != nil || string(key) != "from-env" {
+		t.Fatalf("LoadKey() = %q, %v, want key from env", key, err)
+	}
+
+	t.Setenv(KeyEnv, " \n")
+	t.Setenv(KeyFileEnv, "")
+	if _, err := LoadKey(); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("LoadKey() blank key error = %v, want ErrKeyNotFound", err)
+	}
+}
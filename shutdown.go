@@ -0,0 +1,56 @@
+package gohera
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+var (
+	shutdownHooks []shutdownHook
+	shutdownMu    sync.Mutex
+)
+
+// OnShutdown 注册退出钩子
+// 服务退出时按注册顺序的逆序执行，先注册的资源（如日志）最后释放
+func OnShutdown(name string, fn func(ctx context.Context) error) {
+	if fn == nil {
+		return
+	}
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+	shutdownHooks = append(shutdownHooks, shutdownHook{name: name, fn: fn})
+}
+
+// Shutdown 逆序执行所有已注册的退出钩子
+// 每个钩子只会被执行一次，返回所有钩子的错误合集
+func Shutdown(ctx context.Context) error {
+	shutdownMu.Lock()
+	hooks := shutdownHooks
+	shutdownHooks = nil
+	shutdownMu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := runShutdownHook(ctx, hooks[i]); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown %s: %w", hooks[i].name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runShutdownHook 执行单个退出钩子，钩子内的 panic 不影响后续钩子执行
+func runShutdownHook(ctx context.Context, hook shutdownHook) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return hook.fn(ctx)
+}
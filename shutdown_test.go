@@ -0,0 +1,90 @@
+package gohera
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// resetShutdownHooks 清空已注册的退出钩子，测试结束后恢复
+func resetShutdownHooks(t *testing.T) {
+	shutdownMu.Lock()
+	saved := shutdownHooks
+	shutdownHooks = nil
+	shutdownMu.Unlock()
+	t.Cleanup(func() {
+		shutdownMu.Lock()
+		shutdownHooks = saved
+		shutdownMu.Unlock()
+	})
+}
+
+func TestShutdownReverseOrder(t *testing.T) {
+	resetShutdownHooks(t)
+	var order []string
+	for _, name := range []string{"log", "mysql", "cron"} {
+		OnShutdown(name, func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+	OnShutdown("nil", nil)
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := strings.Join(order, ","); got != "cron,mysql,log" {
+		t.Fatalf("order = %s, want cron,mysql,log", got)
+	}
+
+	// 钩子只执行一次
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("second Shutdown() error = %v", err)
+	}
+	if len(order) != 3 {
+		t.Fatalf("hooks executed %d times, want 3", len(order))
+	}
+}
+
+func TestShutdownCollectsErrors(t *testing.T) {
+	resetShutdownHooks(t)
+	errRedis := errors.New("redis close fail")
+	var ran bool
+	OnShutdown("log", func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	OnShutdown("redis", func(ctx context.Context) error {
+		return errRedis
+	})
+	OnShutdown("kafka", func(ctx context.Context) error {
+		panic("boom")
+	})
+
+	err := Shutdown(context.Background())
+	if !ran {
+		t.Fatal("hooks after a failed hook were not executed")
+	}
+	if !errors.Is(err, errRedis) {
+		t.Fatalf("Shutdown() error = %v, want to wrap %v", err, errRedis)
+	}
+	for _, want := range []string{"shutdown redis: redis close fail", "shutdown kafka: panic: boom"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Shutdown() error = %q, want to contain %q", err, want)
+		}
+	}
+}
+
+func TestShutdownPassesContext(t *testing.T) {
+	resetShutdownHooks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	OnShutdown("wait", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := Shutdown(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Shutdown() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,252 @@
+package gohera
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metlive/gohera/mysql"
+	"github.com/metlive/gohera/redis"
+)
+
+// SpanKind Span 的类型，取值与 OTLP 一致
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1 // 内部调用
+	SpanKindServer   SpanKind = 2 // 接收的请求
+	SpanKindClient   SpanKind = 3 // 发起的请求、SQL 及 Redis 命令
+)
+
+const (
+	SpanStatusUnset = 0
+	SpanStatusOk    = 1
+	SpanStatusError = 2
+)
+
+// spanCtx 当前 Span 在 Context 中的键
+const spanCtx = "span-ctx"
+
+// Span 一次请求、调用或查询的耗时记录，开启 [trace.export] 后导出到 OTLP Collector
+// 方法可以在多个协程中调用，结束后不再修改
+type Span struct {
+	TraceId       string
+	SpanId        string
+	ParentId      string
+	Name          string
+	Kind          SpanKind
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    map[string]any
+	StatusCode    int
+	StatusMessage string
+
+	sampled  bool
+	mu       sync.Mutex // 保护 End 之前对属性、状态及结束时间的修改
+	ended    bool
+	exporter *spanExporter
+}
+
+// NewSpan 创建内部调用的 Span，返回的 Context 用于创建子 Span，结束时调用 End
+// 未开启 [trace.export] 或 ctx 中没有 Trace 信息时返回 nil，Span 的方法均可在 nil 上调用
+func NewSpan(ctx context.Context, name string) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return startSpan(ctx, GetTraceContext(ctx), name, SpanKindInternal, "", currentSpanId(ctx))
+}
+
+// startSpan 创建 Span，spanId 为空时生成新的 ID
+// 采样决定依次使用父 Span、上游传入的决定及 [trace] sample_ratio
+func startSpan(ctx context.Context, t *Trace, name string, kind SpanKind, spanId, parentId string) (context.Context, *Span) {
+	e := traceExporter.Load()
+	if e == nil || t.TraceId == "" {
+		return ctx, nil
+	}
+	if spanId == "" {
+		spanId = newHexSpanId()
+	}
+	s := &Span{
+		TraceId:    otlpTraceId(t.TraceId),
+		SpanId:     spanId,
+		ParentId:   parentId,
+		Name:       name,
+		Kind:       kind,
+		StartTime:  time.Now(),
+		Attributes: make(map[string]any),
+		exporter:   e,
+	}
+	switch parent := SpanFromContext(ctx); {
+	case parent != nil:
+		s.sampled = parent.sampled
+	case t.Sampled != nil:
+		s.sampled = *t.Sampled
+	default:
+		s.sampled = e.sample(s.TraceId)
+	}
+	return context.WithValue(ctx, spanCtx, s), s
+}
+
+// SpanFromContext 获取 Context 中当前的 Span，没有时返回 nil
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(spanCtx).(*Span)
+	return s
+}
+
+// currentSpanId 获取当前 Span 的 ID，作为子 Span 的父 ID
+func currentSpanId(ctx context.Context) string {
+	if s := SpanFromContext(ctx); s != nil {
+		return s.SpanId
+	}
+	return GetTraceContext(ctx).HexSpanId
+}
+
+// SetAttribute 设置属性，value 支持字符串、整数、浮点数及布尔值，其他类型按字符串输出
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.Attributes[key] = value
+	}
+}
+
+// SetError 将 Span 标记为失败，err 为 nil 时忽略
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.StatusCode = SpanStatusError
+		s.StatusMessage = err.Error()
+	}
+}
+
+// Sampled Span 是否被采样，未被采样的 Span 不会导出
+func (s *Span) Sampled() bool {
+	return s != nil && s.sampled
+}
+
+// End 结束 Span 并导出，重复调用时忽略
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+	if s.sampled {
+		s.exporter.export(s)
+	}
+}
+
+// otlpTraceId OTLP 要求 TraceId 为 32 位十六进制，不符合的 TraceId 转换为其 MD5 值，同一 TraceId 在所有服务中的转换结果一致
+func otlpTraceId(traceId string) string {
+	if isHexId(traceId, 32) {
+		return traceId
+	}
+	sum := md5.Sum([]byte(traceId))
+	return hex.EncodeToString(sum[:])
+}
+
+// sampleTraceId 按 TraceId 的低 8 字节判断是否采样，同一条链路在所有服务中的决定一致
+func sampleTraceId(traceId string, ratio float64) bool {
+	if ratio >= 1 {
+		return true
+	}
+	if ratio <= 0 {
+		return false
+	}
+	b, err := hex.DecodeString(traceId[16:])
+	if err != nil {
+		return false
+	}
+	return binary.BigEndian.Uint64(b)>>1 < uint64(ratio*(1<<63))
+}
+
+// querySpan 钩子创建的 Span 在 Context 中的键，与父 Span 区分，避免未创建 Span 时结束父 Span
+type querySpan struct{}
+
+// mysqlTraceHook 为每条 SQL 创建 Span
+type mysqlTraceHook struct{}
+
+// BeforeQuery 实现 mysql.Hook 接口
+func (mysqlTraceHook) BeforeQuery(ctx context.Context, query string, args []any) context.Context {
+	op, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	ctx, s := startSpan(ctx, GetTraceContext(ctx), "mysql "+strings.ToUpper(op), SpanKindClient, "", currentSpanId(ctx))
+	if s == nil {
+		return ctx
+	}
+	s.SetAttribute("db.system", "mysql")
+	s.SetAttribute("db.statement", query)
+	return context.WithValue(ctx, querySpan{}, s)
+}
+
+// AfterQuery 实现 mysql.Hook 接口
+func (mysqlTraceHook) AfterQuery(ctx context.Context, _ string, _ []any, result sql.Result, _ time.Duration, err error) {
+	s, _ := ctx.Value(querySpan{}).(*Span)
+	if s == nil {
+		return
+	}
+	if result != nil {
+		if rows, e := result.RowsAffected(); e == nil {
+			s.SetAttribute("db.rows_affected", rows)
+		}
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		s.SetError(err)
+	}
+	s.End()
+}
+
+// redisTraceHook 为每条 Redis 命令创建 Span
+type redisTraceHook struct{}
+
+// BeforeProcess 实现 redis.Hook 接口
+func (redisTraceHook) BeforeProcess(ctx context.Context, cmd string, args []any) context.Context {
+	ctx, s := startSpan(ctx, GetTraceContext(ctx), "redis "+cmd, SpanKindClient, "", currentSpanId(ctx))
+	if s == nil {
+		return ctx
+	}
+	s.SetAttribute("db.system", "redis")
+	s.SetAttribute("db.operation", cmd)
+	if len(args) > 0 {
+		if key, ok := args[0].(string); ok {
+			s.SetAttribute("db.redis.key", key)
+		}
+	}
+	return context.WithValue(ctx, querySpan{}, s)
+}
+
+// AfterProcess 实现 redis.Hook 接口
+func (redisTraceHook) AfterProcess(ctx context.Context, _ string, _ []any, _ time.Duration, err error) {
+	s, _ := ctx.Value(querySpan{}).(*Span)
+	if s == nil {
+		return
+	}
+	s.SetError(err)
+	s.End()
+}
+
+func init() {
+	mysql.AddHook(mysqlTraceHook{})
+	redis.AddHook(redisTraceHook{})
+}
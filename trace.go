@@ -0,0 +1,76 @@
+package gohera
+
+import (
+	"context"
+	"fmt"
+)
+
+// traceConfig [trace] 配置
+type traceConfig struct {
+	Propagators []string          `mapstructure:"propagators"`                        // 传递 Trace 信息使用的请求头格式，接收请求时按顺序提取，发起请求时全部写入
+	SampleRatio float64           `mapstructure:"sample_ratio" binding:"gte=0,lte=1"` // 没有上游采样决定时 Span 的采样比例
+	Export      traceExportConfig `mapstructure:"export"`
+}
+
+// defaultTraceConfig 默认 Trace 配置，兼容原有的请求头及 W3C 格式
+func defaultTraceConfig() traceConfig {
+	return traceConfig{
+		Propagators: []string{PropagatorLegacy, PropagatorTraceContext, PropagatorBaggage},
+		SampleRatio: 1,
+		Export:      defaultTraceExportConfig(),
+	}
+}
+
+// loadTraceConfig 加载 [trace] 配置
+func loadTraceConfig() (traceConfig, error) {
+	cfg := defaultTraceConfig()
+	if IsSet("trace") {
+		if err := UnmarshalKey("trace", &cfg); err != nil {
+			return cfg, fmt.Errorf("trace config parse fail: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// applyTraceConfig 应用 Trace 配置，替换导出器时导出原导出器中尚未导出的 Span
+func applyTraceConfig(cfg traceConfig) error {
+	cp, err := newPropagator(cfg.Propagators)
+	if err != nil {
+		return err
+	}
+	e, err := newSpanExporter(cfg.Export, cfg.SampleRatio)
+	if err != nil {
+		return err
+	}
+	tracePropagator.Store(&cp)
+	if old := traceExporter.Swap(e); old != nil {
+		old.close()
+	}
+	return nil
+}
+
+// initTrace 根据 [trace] 配置初始化链路追踪，配置热更新时重新应用
+func initTrace() error {
+	cfg, err := loadTraceConfig()
+	if err != nil {
+		return err
+	}
+	if err = applyTraceConfig(cfg); err != nil {
+		return err
+	}
+	OnChange("trace", func(_, _ any) {
+		cfg, err := loadTraceConfig()
+		if err == nil {
+			err = applyTraceConfig(cfg)
+		}
+		if err != nil {
+			Errortf(context.Background(), "trace config fail: %v", err)
+		}
+	})
+	// 在日志之后注册，退出时先导出剩余的 Span
+	OnShutdown("trace", func(ctx context.Context) error {
+		traceExporter.Swap(nil).close()
+		return nil
+	})
+	return nil
+}
@@ -0,0 +1,287 @@
+package gohera
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// traceExportConfig [trace.export] 配置
+type traceExportConfig struct {
+	Enable    bool              `mapstructure:"enable"`
+	Endpoint  string            `mapstructure:"endpoint" binding:"omitempty,url"` // OTLP/HTTP 地址，如 http://otel-collector:4318，未包含路径时使用 /v1/traces
+	Headers   map[string]string `mapstructure:"headers"`
+	Timeout   time.Duration     `mapstructure:"timeout" binding:"gte=0"`    // 单次导出的超时时间
+	BatchSize int               `mapstructure:"batch_size" binding:"gte=0"` // 单次导出的最大 Span 数
+	QueueSize int               `mapstructure:"queue_size" binding:"gte=0"` // 等待导出的最大 Span 数，超过时丢弃
+	Interval  time.Duration     `mapstructure:"interval" binding:"gte=0"`   // 定时导出的间隔
+}
+
+// defaultTraceExportConfig 默认导出配置
+func defaultTraceExportConfig() traceExportConfig {
+	return traceExportConfig{
+		Timeout:   5 * time.Second,
+		BatchSize: 512,
+		QueueSize: 4096,
+		Interval:  5 * time.Second,
+	}
+}
+
+// traceDropped 因队列满或导出失败而丢弃的 Span 数，通过 /metrics 输出
+var traceDropped = expvar.NewInt("trace_dropped")
+
+// spanExporter 以 OTLP/HTTP JSON 格式批量导出 Span
+type spanExporter struct {
+	cfg      traceExportConfig
+	ratio    float64
+	url      string
+	client   *http.Client
+	resource []otlpAttribute
+	queue    chan *Span
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// traceExporter 当前使用的导出器，为 nil 时不创建 Span
+var traceExporter atomic.Pointer[spanExporter]
+
+// newSpanExporter 根据配置创建导出器并启动后台导出协程，未开启时返回 nil
+func newSpanExporter(cfg traceExportConfig, ratio float64) (*spanExporter, error) {
+	if !cfg.Enable {
+		return nil, nil
+	}
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("trace.export.endpoint is required")
+	}
+	def := defaultTraceExportConfig()
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = def.QueueSize
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	u := strings.TrimRight(cfg.Endpoint, "/")
+	if !strings.HasSuffix(u, "/v1/traces") {
+		u += "/v1/traces"
+	}
+
+	service := GetAppName()
+	if service == "" {
+		service = GetString("http.service")
+	}
+	resource := map[string]any{
+		"service.name":           service,
+		"service.version":        GetAppVersion(),
+		"deployment.environment": GetEnv(),
+		"k8s.namespace.name":     GetAppNamespace(),
+		"k8s.pod.name":           GetAppPodName(),
+		"telemetry.sdk.name":     "gohera",
+		"telemetry.sdk.language": "go",
+	}
+	e := &spanExporter{
+		cfg:   cfg,
+		ratio: ratio,
+		url:   u,
+		// 不使用 NewRequest，避免导出请求本身再产生 Span
+		client:   &http.Client{Transport: defaultHTTPClient.Transport, Timeout: cfg.Timeout},
+		resource: otlpAttributes(resource),
+		queue:    make(chan *Span, cfg.QueueSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go e.run()
+	return e, nil
+}
+
+// sample 按采样比例判断是否采样
+func (e *spanExporter) sample(traceId string) bool {
+	return sampleTraceId(traceId, e.ratio)
+}
+
+// export 将 Span 加入导出队列，队列满时丢弃
+func (e *spanExporter) export(s *Span) {
+	select {
+	case e.queue <- s:
+	default:
+		traceDropped.Add(1)
+	}
+}
+
+// run 后台导出协程，达到 batch_size 或定时导出
+func (e *spanExporter) run() {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+	defer close(e.done)
+	batch := make([]*Span, 0, e.cfg.BatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			e.send(batch)
+			batch = make([]*Span, 0, e.cfg.BatchSize)
+		}
+	}
+	for {
+		select {
+		case s := <-e.queue:
+			batch = append(batch, s)
+			if len(batch) >= e.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.stop:
+			for {
+				select {
+				case s := <-e.queue:
+					batch = append(batch, s)
+					if len(batch) >= e.cfg.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// close 停止导出器，等待队列中的 Span 导出完成
+func (e *spanExporter) close() {
+	if e != nil {
+		close(e.stop)
+		<-e.done
+	}
+}
+
+// send 导出一批 Span，失败时以 Warn 级别记录
+func (e *spanExporter) send(spans []*Span) {
+	body, err := json.Marshal(e.payload(spans))
+	if err == nil {
+		err = e.post(body)
+	}
+	if err != nil {
+		traceDropped.Add(int64(len(spans)))
+		Named("trace").Warntf(context.Background(), "export %d spans fail: %v", len(spans), err)
+	}
+}
+
+// post 发送 OTLP 请求
+func (e *spanExporter) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	rsp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("status %d", rsp.StatusCode)
+	}
+	return nil
+}
+
+// otlpAttribute OTLP 的属性
+type otlpAttribute struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+// otlpSpan OTLP 的 Span
+type otlpSpan struct {
+	TraceId           string          `json:"traceId"`
+	SpanId            string          `json:"spanId"`
+	ParentSpanId      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+// otlpStatus OTLP 的 Span 状态
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// payload 生成 OTLP/HTTP JSON 格式的请求内容
+func (e *spanExporter) payload(spans []*Span) map[string]any {
+	list := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		list = append(list, otlpSpan{
+			TraceId:           s.TraceId,
+			SpanId:            s.SpanId,
+			ParentSpanId:      s.ParentId,
+			Name:              s.Name,
+			Kind:              s.Kind,
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.Attributes),
+			Status:            otlpStatus{Code: s.StatusCode, Message: s.StatusMessage},
+		})
+	}
+	return map[string]any{
+		"resourceSpans": []any{
+			map[string]any{
+				"resource": map[string]any{"attributes": e.resource},
+				"scopeSpans": []any{
+					map[string]any{
+						"scope": map[string]any{"name": "github.com/metlive/gohera"},
+						"spans": list,
+					},
+				},
+			},
+		},
+	}
+}
+
+// otlpAttributes 将属性转换为 OTLP 格式，按 key 排序，忽略空字符串
+func otlpAttributes(attrs map[string]any) []otlpAttribute {
+	list := make([]otlpAttribute, 0, len(attrs))
+	for k, v := range attrs {
+		var value map[string]any
+		switch val := v.(type) {
+		case string:
+			if val == "" {
+				continue
+			}
+			value = map[string]any{"stringValue": val}
+		case bool:
+			value = map[string]any{"boolValue": val}
+		case int:
+			value = map[string]any{"intValue": strconv.Itoa(val)}
+		case int64:
+			value = map[string]any{"intValue": strconv.FormatInt(val, 10)}
+		case float64:
+			value = map[string]any{"doubleValue": val}
+		default:
+			value = map[string]any{"stringValue": fmt.Sprint(val)}
+		}
+		list = append(list, otlpAttribute{Key: k, Value: value})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
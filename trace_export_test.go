@@ -0,0 +1,266 @@
+package gohera
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// otlpRequest Collector 收到的导出请求
+type otlpRequest struct {
+	path   string
+	header http.Header
+	body   struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []otlpAttribute `json:"attributes"`
+			} `json:"resource"`
+			ScopeSpans []struct {
+				Spans []otlpSpan `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+}
+
+// spans 请求中的 Span
+func (r *otlpRequest) spans() []otlpSpan {
+	return r.body.ResourceSpans[0].ScopeSpans[0].Spans
+}
+
+// newTestCollector 创建 OTLP Collector，收到的请求写入返回的 chan，status 返回 true 前阻塞
+func newTestCollector(t *testing.T, status func() int) (*httptest.Server, chan *otlpRequest) {
+	requests := make(chan *otlpRequest, 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &otlpRequest{path: r.URL.Path, header: r.Header.Clone()}
+		if err := json.NewDecoder(r.Body).Decode(&req.body); err != nil {
+			t.Errorf("invalid OTLP request: %v", err)
+		}
+		requests <- req
+		if status != nil {
+			w.WriteHeader(status())
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+// setTestExporter 使用 cfg 创建导出器作为当前导出器，测试结束后关闭并恢复
+func setTestExporter(t *testing.T, cfg traceExportConfig, ratio float64) *spanExporter {
+	cfg.Enable = true
+	e, err := newSpanExporter(cfg, ratio)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := traceExporter.Swap(e)
+	t.Cleanup(func() {
+		traceExporter.Swap(saved).close()
+	})
+	return e
+}
+
+// receiveOtlp 等待下一个导出请求
+func receiveOtlp(t *testing.T, requests chan *otlpRequest) *otlpRequest {
+	t.Helper()
+	select {
+	case req := <-requests:
+		return req
+	case <-time.After(2 * time.Second):
+		t.Fatal("no spans exported")
+		return nil
+	}
+}
+
+// endTestSpans 在同一个请求中创建并结束 n 个 Span
+func endTestSpans(n int) {
+	ctx := context.WithValue(context.Background(), TraceCtx, &Trace{TraceId: testHexTraceId, HexSpanId: testHexSpanId})
+	for i := 0; i < n; i++ {
+		_, span := NewSpan(ctx, "step")
+		span.SetAttribute("index", i)
+		span.End()
+	}
+}
+
+func TestSpanExportBatch(t *testing.T) {
+	srv, requests := newTestCollector(t, nil)
+	e := setTestExporter(t, traceExportConfig{Endpoint: srv.URL + "/", Headers: map[string]string{"Authorization": "Bearer abc"}, BatchSize: 2, Interval: time.Hour}, 1)
+
+	endTestSpans(5)
+	for i := 0; i < 2; i++ {
+		req := receiveOtlp(t, requests)
+		if req.path != "/v1/traces" || req.header.Get("Authorization") != "Bearer abc" || req.header.Get("Content-Type") != "application/json" {
+			t.Fatalf("path = %s, header = %v", req.path, req.header)
+		}
+		if n := len(req.spans()); n != 2 {
+			t.Fatalf("batch %d has %d spans, want 2", i, n)
+		}
+	}
+	select {
+	case req := <-requests:
+		t.Fatalf("exported %d spans before the batch is full", len(req.spans()))
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// 关闭时导出剩余的 Span
+	e.close()
+	traceExporter.Store(nil)
+	req := receiveOtlp(t, requests)
+	spans := req.spans()
+	if len(spans) != 1 {
+		t.Fatalf("exported %d spans on close, want 1", len(spans))
+	}
+	s := spans[0]
+	if s.TraceId != testHexTraceId || s.ParentSpanId != testHexSpanId || s.Name != "step" || s.Kind != SpanKindInternal || s.StartTimeUnixNano == "" {
+		t.Fatalf("span = %+v", s)
+	}
+	if len(s.Attributes) != 1 || s.Attributes[0].Key != "index" || s.Attributes[0].Value["intValue"] != "4" {
+		t.Fatalf("attributes = %+v", s.Attributes)
+	}
+	var sdk string
+	for _, a := range req.body.ResourceSpans[0].Resource.Attributes {
+		if a.Key == "telemetry.sdk.name" {
+			sdk, _ = a.Value["stringValue"].(string)
+		}
+	}
+	if sdk != "gohera" {
+		t.Fatalf("resource = %+v", req.body.ResourceSpans[0].Resource.Attributes)
+	}
+}
+
+func TestSpanExportInterval(t *testing.T) {
+	srv, requests := newTestCollector(t, nil)
+	setTestExporter(t, traceExportConfig{Endpoint: srv.URL + "/v1/traces", Interval: 10 * time.Millisecond}, 1)
+
+	ctx := context.WithValue(context.Background(), TraceCtx, &Trace{TraceId: "legacy-trace"})
+	_, span := NewSpan(ctx, "step")
+	span.SetError(errors.New("timeout"))
+	span.End()
+	span.End()
+
+	req := receiveOtlp(t, requests)
+	if req.path != "/v1/traces" || len(req.spans()) != 1 {
+		t.Fatalf("path = %s, spans = %+v", req.path, req.spans())
+	}
+	// 不符合格式的 TraceId 转换为 MD5
+	s := req.spans()[0]
+	if s.TraceId != otlpTraceId("legacy-trace") || !isHexId(s.TraceId, 32) || s.Status.Code != SpanStatusError || s.Status.Message != "timeout" {
+		t.Fatalf("span = %+v", s)
+	}
+}
+
+func TestSpanConcurrentAttributes(t *testing.T) {
+	srv, requests := newTestCollector(t, nil)
+	setTestExporter(t, traceExportConfig{Endpoint: srv.URL, Interval: 10 * time.Millisecond}, 1)
+
+	ctx := context.WithValue(context.Background(), TraceCtx, &Trace{TraceId: testHexTraceId, HexSpanId: testHexSpanId})
+	_, span := NewSpan(ctx, "fanout")
+	// 多个协程同时设置属性时结束 Span，go test -race 时检查数据竞争
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for j := 0; j < 50; j++ {
+				span.SetAttribute("worker."+strconv.Itoa(i), j)
+				span.SetError(errors.New("partial failure"))
+			}
+		}()
+	}
+	close(start)
+	time.Sleep(time.Millisecond)
+	span.End()
+	wg.Wait()
+	if spans := receiveOtlp(t, requests).spans(); len(spans) != 1 || spans[0].Name != "fanout" {
+		t.Fatalf("spans = %+v", spans)
+	}
+}
+
+func TestSpanExportDropped(t *testing.T) {
+	gate := make(chan struct{})
+	status := http.StatusOK
+	srv, requests := newTestCollector(t, func() int {
+		<-gate
+		return status
+	})
+	e := setTestExporter(t, traceExportConfig{Endpoint: srv.URL, BatchSize: 1, QueueSize: 1, Interval: time.Hour}, 1)
+	before := traceDropped.Value()
+
+	// 第一个 Span 导出时阻塞，第二个进入队列，之后的 Span 被丢弃
+	endTestSpans(1)
+	receiveOtlp(t, requests)
+	endTestSpans(3)
+	if got := traceDropped.Value() - before; got != 2 {
+		t.Fatalf("dropped = %d, want 2", got)
+	}
+
+	// 导出失败的 Span 计入丢弃数
+	status = http.StatusInternalServerError
+	close(gate)
+	e.close()
+	traceExporter.Store(nil)
+	receiveOtlp(t, requests)
+	if got := traceDropped.Value() - before; got != 4 {
+		t.Fatalf("dropped = %d, want 4", got)
+	}
+}
+
+func TestSpanSampling(t *testing.T) {
+	srv, _ := newTestCollector(t, nil)
+	setTestExporter(t, traceExportConfig{Endpoint: srv.URL}, 0)
+
+	sampled := true
+	ctx := context.WithValue(context.Background(), TraceCtx, &Trace{TraceId: testHexTraceId})
+	if _, span := NewSpan(ctx, "step"); span.Sampled() {
+		t.Fatal("span should not be sampled with ratio 0")
+	}
+	// 上游的采样决定优先，子 Span 使用父 Span 的决定
+	ctx = context.WithValue(context.Background(), TraceCtx, &Trace{TraceId: testHexTraceId, Sampled: &sampled})
+	ctx, parent := NewSpan(ctx, "parent")
+	_, child := NewSpan(ctx, "child")
+	if !parent.Sampled() || !child.Sampled() || child.ParentId != parent.SpanId {
+		t.Fatalf("parent = %+v, child = %+v", parent, child)
+	}
+
+	if _, span := NewSpan(context.Background(), "no trace"); span != nil {
+		t.Fatal("NewSpan() should return nil without trace")
+	}
+	traceExporter.Store(nil)
+	if _, span := NewSpan(ctx, "disabled"); span != nil || span.Sampled() {
+		t.Fatal("NewSpan() should return nil when export is disabled")
+	}
+}
+
+func TestSampleTraceId(t *testing.T) {
+	var kept int
+	for i := 0; i < 1000; i++ {
+		traceId := otlpTraceId(string(rune(i)))
+		if sampleTraceId(traceId, 0.3) != sampleTraceId(traceId, 0.3) {
+			t.Fatal("sampling should be consistent")
+		}
+		if sampleTraceId(traceId, 0.3) {
+			kept++
+			if !sampleTraceId(traceId, 0.6) {
+				t.Fatal("trace sampled at 0.3 should be sampled at 0.6")
+			}
+		}
+	}
+	if kept < 200 || kept > 400 {
+		t.Fatalf("kept %d of 1000 traces at ratio 0.3", kept)
+	}
+}
+
+func TestNewSpanExporter(t *testing.T) {
+	if e, err := newSpanExporter(traceExportConfig{}, 1); e != nil || err != nil {
+		t.Fatalf("newSpanExporter() = %v, %v, want nil when disabled", e, err)
+	}
+	if _, err := newSpanExporter(traceExportConfig{Enable: true}, 1); err == nil {
+		t.Fatal("newSpanExporter() should fail without endpoint")
+	}
+}
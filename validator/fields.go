@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales/zh"
+	"github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+// FieldError 字段验证错误
+type FieldError struct {
+	Field   string // 字段路径，使用 tagName 指定的标签名，如 mysql.port
+	Message string // 中文错误消息
+}
+
+type fieldValidator struct {
+	validate *validator.Validate
+	trans    ut.Translator
+}
+
+var (
+	fieldValidators = make(map[string]*fieldValidator)
+	fieldMu         sync.Mutex
+)
+
+// ValidateFields 验证结构体并返回所有字段的错误
+// 验证规则使用 binding 标签，字段名取 tagName 指定的标签（如 mapstructure），未设置时使用小写字段名
+func ValidateFields(obj any, tagName string) ([]FieldError, error) {
+	if kindOfData(obj) != reflect.Struct {
+		return nil, nil
+	}
+	fv := getFieldValidator(tagName)
+	err := fv.validate.Struct(obj)
+	if err == nil {
+		return nil, nil
+	}
+	errs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil, err
+	}
+	result := make([]FieldError, 0, len(errs))
+	for _, e := range errs {
+		field := e.Namespace()
+		// 去掉根结构体名称
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		result = append(result, FieldError{Field: field, Message: e.Translate(fv.trans)})
+	}
+	return result, nil
+}
+
+func getFieldValidator(tagName string) *fieldValidator {
+	fieldMu.Lock()
+	defer fieldMu.Unlock()
+	if fv, ok := fieldValidators[tagName]; ok {
+		return fv
+	}
+
+	validate := validator.New()
+	validate.SetTagName("binding")
+	zhCn := zh.New()
+	trans, _ := ut.New(zhCn, zhCn).GetTranslator("zh")
+	_ = zhtranslations.RegisterDefaultTranslations(validate, trans)
+	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get(tagName), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			name = strings.ToLower(fld.Name)
+		}
+		return name
+	})
+	validate.RegisterValidation("ipv4", IsIp4)
+	validate.RegisterValidation("YYYY-MM-DD", IsYMD)
+	validate.RegisterValidation("YYYY-MM-DD HH:mm", IsYMDHM)
+	validate.RegisterValidation("YYYY-MM-DD HH:mm:ss", IsYMDHMS)
+
+	fv := &fieldValidator{validate: validate, trans: trans}
+	fieldValidators[tagName] = fv
+	return fv
+}